@@ -237,3 +237,19 @@ func (a *appInstance) GetAgents() []string {
 	}
 	return agentNames
 }
+
+func (a *appInstance) GetStats(name string) map[string]int64 {
+	if agent, ok := a.agents[name]; ok {
+		return agent.GetStats()
+	}
+
+	return nil
+}
+
+func (a *appInstance) GetDeadLetter(name string) string {
+	if agent, ok := a.agents[name]; ok {
+		return agent.GetDeadLetter()
+	}
+
+	return ""
+}
@@ -1,10 +1,16 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/internal/input/command"
 	"github.com/fugo-app/fugo/internal/input/file"
+	"github.com/fugo-app/fugo/internal/input/journal"
+	"github.com/fugo-app/fugo/internal/input/kmsg"
 	"github.com/fugo-app/fugo/internal/input/system"
 	"github.com/fugo-app/fugo/internal/storage"
 )
@@ -18,16 +24,57 @@ type Agent struct {
 	// File-based input.
 	File *file.FileWatcher `yaml:"file,omitempty"`
 
+	// Command-based input.
+	Exec *command.CommandWatcher `yaml:"exec,omitempty"`
+
+	// Kernel log input.
+	Kmsg *kmsg.KmsgWatcher `yaml:"kmsg,omitempty"`
+
+	// Systemd journal input.
+	Journal *journal.JournalWatcher `yaml:"journal,omitempty"`
+
 	// System telemetry input.
 	System *system.SystemWatcher `yaml:"system,omitempty"`
 
+	// Keep only records matching all expressions.
+	// Example: ["status >= 400", "raw.path !~ \"^/health\""]
+	Filter []string `yaml:"filter,omitempty"`
+
+	// Drop records matching any expression.
+	// Example: ["level == \"debug\""]
+	Drop []string `yaml:"drop,omitempty"`
+
+	// GeoIP databases to add location and network of the IP address to the record.
+	GeoIP []*GeoIPConfig `yaml:"geoip,omitempty"`
+
+	// Lookup tables to add columns to the record by the field value.
+	Lookup []*LookupConfig `yaml:"lookup,omitempty"`
+
+	// Sampling to keep only a fraction of records.
+	Sampling *SamplingConfig `yaml:"sampling,omitempty"`
+
+	// Limit of records written per second.
+	RateLimit *RateLimitConfig `yaml:"rate_limit,omitempty"`
+
 	// Retention configuration
 	Retention storage.RetentionConfig `yaml:"retention,omitempty"`
 
+	// Dead-letter capture for lines that could not be parsed or converted.
+	DeadLetter *DeadLetterConfig `yaml:"dead_letter,omitempty"`
+
 	fields []*field.Field
+	flags  map[string]bool // flag fields for the time fields with on_error "flag"
+	enrich map[string]bool // fields added by the geoip and lookup enrichments
+	rules  recordRules
 	app    AppHandler
+	stats  agentStats
 }
 
+// timeFallbackSuffix is the suffix of the flag field for the time field with on_error "flag".
+const timeFallbackSuffix = "_fallback"
+
+var stdTimeNow = time.Now
+
 func (a *Agent) Init(name string, app AppHandler) error {
 	a.app = app
 
@@ -36,10 +83,12 @@ func (a *Agent) Init(name string, app AppHandler) error {
 	}
 	a.name = name
 
-	if len(a.Fields) == 0 {
-		if a.System != nil {
-			a.fields = a.System.Fields()
+	if a.System != nil {
+		fields, err := a.System.SelectFields(a.Fields)
+		if err != nil {
+			return fmt.Errorf("system fields: %w", err)
 		}
+		a.fields = fields
 	} else {
 		a.fields = make([]*field.Field, len(a.Fields))
 		for i := range a.Fields {
@@ -64,12 +113,51 @@ func (a *Agent) Init(name string, app AppHandler) error {
 		return fmt.Errorf("time field is required")
 	}
 
+	if err := a.initFlags(); err != nil {
+		return err
+	}
+
+	if err := a.initEnrich(); err != nil {
+		return err
+	}
+
+	// System input writes records without raw data
+	rules, err := newRecordRules(a.Filter, a.Drop, a.fields, a.System == nil)
+	if err != nil {
+		return err
+	}
+	a.rules = rules
+
+	if a.Sampling != nil {
+		if err := a.Sampling.Init(a.fields); err != nil {
+			return fmt.Errorf("sampling init: %w", err)
+		}
+	}
+
 	if a.File != nil {
 		if err := a.File.Init(a); err != nil {
 			return fmt.Errorf("file agent init: %w", err)
 		}
 	}
 
+	if a.Exec != nil {
+		if err := a.Exec.Init(a); err != nil {
+			return fmt.Errorf("exec agent init: %w", err)
+		}
+	}
+
+	if a.Kmsg != nil {
+		if err := a.Kmsg.Init(a); err != nil {
+			return fmt.Errorf("kmsg agent init: %w", err)
+		}
+	}
+
+	if a.Journal != nil {
+		if err := a.Journal.Init(a); err != nil {
+			return fmt.Errorf("journal agent init: %w", err)
+		}
+	}
+
 	if a.System != nil {
 		if err := a.System.Init(a); err != nil {
 			return fmt.Errorf("system agent init: %w", err)
@@ -84,6 +172,99 @@ func (a *Agent) Init(name string, app AppHandler) error {
 		return fmt.Errorf("migrate agent (%s): %w", name, err)
 	}
 
+	if a.DeadLetter != nil {
+		if err := a.DeadLetter.Init(name, a.app.GetStorage()); err != nil {
+			return fmt.Errorf("dead letter init: %w", err)
+		}
+	}
+
+	if a.RateLimit != nil {
+		if err := a.RateLimit.Init(name, a.app.GetStorage()); err != nil {
+			return fmt.Errorf("rate limit init: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// initFlags adds the bool field "<name>_fallback" for each time field with on_error "flag".
+func (a *Agent) initFlags() error {
+	a.flags = nil
+
+	for _, f := range a.fields {
+		if f.OnError != "flag" {
+			continue
+		}
+
+		flag := &field.Field{
+			Name:        f.Name + timeFallbackSuffix,
+			Type:        "bool",
+			Description: fmt.Sprintf("Ingestion time is used for %s", f.Name),
+		}
+		for _, other := range a.fields {
+			if other.Name == flag.Name {
+				return fmt.Errorf("field %s: conflicts with on_error flag of %s", flag.Name, f.Name)
+			}
+		}
+		if err := flag.Init(); err != nil {
+			return fmt.Errorf("field %s init: %w", flag.Name, err)
+		}
+
+		if a.flags == nil {
+			a.flags = make(map[string]bool)
+		}
+		a.flags[flag.Name] = true
+		a.fields = append(a.fields, flag)
+	}
+
+	return nil
+}
+
+// initEnrich loads the geoip databases and the lookup tables
+// and adds their fields to the agent fields.
+func (a *Agent) initEnrich() error {
+	a.enrich = nil
+
+	if (len(a.GeoIP) > 0 || len(a.Lookup) > 0) && a.System != nil {
+		return fmt.Errorf("enrichment is not supported for system input")
+	}
+
+	for i, geoip := range a.GeoIP {
+		if err := geoip.Init(a.fields); err != nil {
+			return fmt.Errorf("geoip #%d init: %w", i+1, err)
+		}
+
+		if err := a.addEnrichFields(geoip.fields); err != nil {
+			return fmt.Errorf("geoip #%d: %w", i+1, err)
+		}
+	}
+
+	for i, lookup := range a.Lookup {
+		if err := lookup.Init(a.fields); err != nil {
+			return fmt.Errorf("lookup #%d init: %w", i+1, err)
+		}
+
+		if err := a.addEnrichFields(lookup.fields); err != nil {
+			return fmt.Errorf("lookup #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Agent) addEnrichFields(fields []*field.Field) error {
+	for _, f := range fields {
+		if slices.ContainsFunc(a.fields, func(other *field.Field) bool { return other.Name == f.Name }) {
+			return fmt.Errorf("field %s is already defined", f.Name)
+		}
+
+		if a.enrich == nil {
+			a.enrich = make(map[string]bool)
+		}
+		a.enrich[f.Name] = true
+		a.fields = append(a.fields, f)
+	}
+
 	return nil
 }
 
@@ -92,11 +273,39 @@ func (a *Agent) Start() {
 		a.File.Start()
 	}
 
+	if a.Exec != nil {
+		a.Exec.Start()
+	}
+
+	if a.Kmsg != nil {
+		a.Kmsg.Start()
+	}
+
+	if a.Journal != nil {
+		a.Journal.Start()
+	}
+
 	if a.System != nil {
 		a.System.Start()
 	}
 
 	a.Retention.Start()
+
+	for _, geoip := range a.GeoIP {
+		geoip.Start()
+	}
+
+	for _, lookup := range a.Lookup {
+		lookup.Start()
+	}
+
+	if a.DeadLetter != nil {
+		a.DeadLetter.Start()
+	}
+
+	if a.RateLimit != nil {
+		a.RateLimit.Start()
+	}
 }
 
 func (a *Agent) Stop() {
@@ -104,30 +313,129 @@ func (a *Agent) Stop() {
 		a.File.Stop()
 	}
 
+	if a.Exec != nil {
+		a.Exec.Stop()
+	}
+
+	if a.Kmsg != nil {
+		a.Kmsg.Stop()
+	}
+
+	if a.Journal != nil {
+		a.Journal.Stop()
+	}
+
 	if a.System != nil {
 		a.System.Stop()
 	}
 
 	a.Retention.Stop()
+
+	for _, geoip := range a.GeoIP {
+		geoip.Stop()
+	}
+
+	for _, lookup := range a.Lookup {
+		lookup.Stop()
+	}
+
+	if a.DeadLetter != nil {
+		a.DeadLetter.Stop()
+	}
+
+	if a.RateLimit != nil {
+		a.RateLimit.Stop()
+	}
 }
 
-func (a *Agent) Serialize(data map[string]string) map[string]any {
+// convertError is returned by Serialize if some fields could not be converted.
+type convertError struct {
+	err error
+}
+
+func (e *convertError) Error() string {
+	return e.err.Error()
+}
+
+func (e *convertError) Unwrap() error {
+	return e.err
+}
+
+func (a *Agent) Serialize(data map[string]string) (map[string]any, error) {
 	if len(data) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	result := make(map[string]any)
 
+	var errs []error
+	var drop bool
+
 	for i := range a.fields {
 		field := a.fields[i]
-		if val, err := field.Convert(data); err == nil && val != nil {
+		if a.enrich[field.Name] {
+			continue
+		}
+
+		if a.flags[field.Name] {
+			// Flag is set by the time field defined before
+			if _, ok := result[field.Name]; !ok {
+				result[field.Name] = false
+			}
+			continue
+		}
+
+		val, err := field.ConvertRecord(data, result)
+		if err == nil && val != nil {
 			result[field.Name] = val
-		} else {
-			result[field.Name] = field.Default()
+			continue
+		}
+
+		switch field.OnError {
+		case "now", "flag":
+			// Record is stored with the ingestion time instead of the zero time
+			// to keep it visible in time-bounded queries and retention.
+			result[field.Name] = stdTimeNow().UnixMilli()
+			if field.OnError == "flag" {
+				result[field.Name+timeFallbackSuffix] = true
+			}
+			a.stats.add("time_fallbacks", 1)
+			continue
+		case "drop":
+			if err == nil {
+				err = fmt.Errorf("missing value")
+			}
+			drop = true
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
 		}
+		result[field.Name] = field.Default()
+	}
+
+	if drop {
+		return nil, &convertError{errors.Join(errs...)}
+	}
+
+	for _, geoip := range a.GeoIP {
+		geoip.enrich(result)
 	}
 
-	return result
+	for _, lookup := range a.Lookup {
+		lookup.enrich(result)
+	}
+
+	if !a.rules.keep(result, data) {
+		a.stats.add("dropped", 1)
+		return nil, nil
+	}
+
+	if len(errs) > 0 {
+		return result, &convertError{errors.Join(errs...)}
+	}
+
+	return result, nil
 }
 
 // Write writes the serialized data to the storage.
@@ -136,10 +444,55 @@ func (a *Agent) Write(data map[string]any) {
 		return
 	}
 
+	if !a.rules.keep(data, nil) {
+		a.stats.add("dropped", 1)
+		return
+	}
+
+	if a.Sampling != nil && !a.Sampling.sample(data) {
+		a.stats.add("sampled_out", 1)
+		return
+	}
+
+	if a.RateLimit != nil && !a.RateLimit.allow() {
+		a.stats.add("rate_limited", 1)
+		return
+	}
+
 	a.app.GetStorage().Write(a.name, data)
+	a.stats.add("written", 1)
+}
+
+// Reject counts the rejected line and saves it to the dead-letter table if enabled.
+func (a *Agent) Reject(source string, offset int64, line string, reason error) {
+	var ce *convertError
+	if errors.As(reason, &ce) {
+		a.stats.add("convert_errors", 1)
+	} else {
+		a.stats.add("parse_errors", 1)
+	}
+
+	if a.DeadLetter != nil {
+		a.DeadLetter.Write(source, offset, line, reason)
+		a.stats.add("dead_letters", 1)
+	}
 }
 
 // GetFields returns the list of initialized fields for the agent.
 func (a *Agent) GetFields() []*field.Field {
 	return a.fields
 }
+
+// GetStats returns the pipeline counters for the agent.
+func (a *Agent) GetStats() map[string]int64 {
+	return a.stats.snapshot()
+}
+
+// GetDeadLetter returns the name of the dead-letter table or empty string if disabled.
+func (a *Agent) GetDeadLetter() string {
+	if a.DeadLetter == nil {
+		return ""
+	}
+
+	return a.DeadLetter.Name()
+}
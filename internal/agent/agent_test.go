@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type testApp struct {
+	storage storage.StorageDriver
+}
+
+func (ta *testApp) GetStorage() storage.StorageDriver {
+	return ta.storage
+}
+
+func TestAgent_Serialize_OnError(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stdNow := stdTimeNow
+	stdTimeNow = func() time.Time { return now }
+	t.Cleanup(func() { stdTimeNow = stdNow })
+
+	newAgent := func(onError string) *Agent {
+		a := &Agent{
+			Fields: []*field.Field{
+				{
+					Name:      "time",
+					Timestamp: &field.TimestampFormat{Format: "rfc3339"},
+					OnError:   onError,
+				},
+				{Name: "message"},
+			},
+		}
+		require.NoError(t, a.Init("test", &testApp{&testStorage{}}))
+		return a
+	}
+
+	valid := map[string]string{"time": "2023-01-01T12:00:00Z", "message": "ok"}
+	invalid := map[string]string{"time": "yesterday", "message": "late"}
+	missing := map[string]string{"message": "lost"}
+
+	t.Run("default", func(t *testing.T) {
+		a := newAgent("")
+
+		result, err := a.Serialize(invalid)
+		require.Error(t, err)
+		require.Equal(t, map[string]any{"time": int64(0), "message": "late"}, result)
+	})
+
+	t.Run("now", func(t *testing.T) {
+		a := newAgent("now")
+
+		result, err := a.Serialize(invalid)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"time": now.UnixMilli(), "message": "late"}, result)
+
+		result, err = a.Serialize(missing)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"time": now.UnixMilli(), "message": "lost"}, result)
+
+		require.Equal(t, int64(2), a.GetStats()["time_fallbacks"])
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		a := newAgent("drop")
+
+		result, err := a.Serialize(invalid)
+		require.ErrorContains(t, err, "field time: invalid timestamp")
+		require.Nil(t, result)
+
+		result, err = a.Serialize(missing)
+		require.ErrorContains(t, err, "field time: missing value")
+		require.Nil(t, result)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		a := newAgent("flag")
+		require.Equal(t, "time_fallback", a.GetFields()[2].Name)
+		require.Equal(t, "bool", a.GetFields()[2].Type)
+
+		result, err := a.Serialize(valid)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"time":          int64(1672574400000),
+			"message":       "ok",
+			"time_fallback": false,
+		}, result)
+
+		result, err = a.Serialize(invalid)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"time":          now.UnixMilli(),
+			"message":       "late",
+			"time_fallback": true,
+		}, result)
+	})
+}
+
+func TestAgent_Init_OnError(t *testing.T) {
+	a := &Agent{
+		Fields: []*field.Field{
+			{Name: "time", Type: "time", OnError: "flag"},
+			{Name: "time_fallback", Type: "bool"},
+		},
+	}
+	require.ErrorContains(t, a.Init("test", &testApp{&testStorage{}}), "conflicts with on_error flag of time")
+
+	a = &Agent{
+		Fields: []*field.Field{
+			{Name: "time", Type: "time"},
+			{Name: "status", Type: "int", OnError: "now"},
+		},
+	}
+	require.ErrorContains(t, a.Init("test", &testApp{&testStorage{}}), "on_error is allowed only for time field 'status'")
+}
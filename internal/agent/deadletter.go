@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/internal/storage"
+)
+
+// DeadLetterConfig enables capturing of lines that could not be parsed or converted.
+// Lines are stored in the separate table named "<agent>__dead_letter".
+type DeadLetterConfig struct {
+	// Retention configuration for the dead-letter table.
+	Retention storage.RetentionConfig `yaml:"retention,omitempty"`
+
+	name    string
+	storage storage.StorageDriver
+}
+
+const deadLetterSuffix = "__dead_letter"
+
+var deadLetterFields = []*field.Field{
+	{
+		Name:        "time",
+		Type:        "time",
+		Description: "Time when the line was rejected",
+	},
+	{
+		Name:        "source",
+		Type:        "string",
+		Description: "Origin of the line, e.g. file path",
+	},
+	{
+		Name:        "offset",
+		Type:        "int",
+		Description: "Position of the line in the source",
+	},
+	{
+		Name:        "reason",
+		Type:        "string",
+		Description: "Error reason",
+	},
+	{
+		Name:        "line",
+		Type:        "string",
+		Description: "Raw line",
+	},
+}
+
+func (dl *DeadLetterConfig) Init(name string, storage storage.StorageDriver) error {
+	dl.name = name + deadLetterSuffix
+	dl.storage = storage
+
+	fields := make([]*field.Field, len(deadLetterFields))
+	for i := range deadLetterFields {
+		fields[i] = deadLetterFields[i].Clone()
+		if err := fields[i].Init(); err != nil {
+			return fmt.Errorf("field %s init: %w", fields[i].Name, err)
+		}
+	}
+
+	if err := dl.Retention.Init(dl.name, "time", storage); err != nil {
+		return fmt.Errorf("retention init: %w", err)
+	}
+
+	if err := storage.Migrate(dl.name, fields); err != nil {
+		return fmt.Errorf("migrate table (%s): %w", dl.name, err)
+	}
+
+	return nil
+}
+
+func (dl *DeadLetterConfig) Start() {
+	dl.Retention.Start()
+}
+
+func (dl *DeadLetterConfig) Stop() {
+	dl.Retention.Stop()
+}
+
+// Name returns the name of the dead-letter table.
+func (dl *DeadLetterConfig) Name() string {
+	return dl.name
+}
+
+func (dl *DeadLetterConfig) Write(source string, offset int64, line string, reason error) {
+	dl.storage.Write(dl.name, map[string]any{
+		"time":   time.Now().UnixMilli(),
+		"source": source,
+		"offset": offset,
+		"reason": reason.Error(),
+		"line":   line,
+	})
+}
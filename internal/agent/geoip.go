@@ -0,0 +1,300 @@
+package agent
+
+import (
+	"container/list"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+// GeoIPConfig enriches records with the location or network of the IP address
+// from the MaxMind DB file, e.g. GeoLite2 City or GeoLite2 ASN.
+// The database is reloaded when the file changes.
+//
+//	geoip:
+//	  - path: /var/lib/GeoIP/GeoLite2-City.mmdb
+//	    source: client_ip
+//	    fields:
+//	      - name: country
+//	      - name: city
+//	        source: city.names.de
+//	  - path: /var/lib/GeoIP/GeoLite2-ASN.mmdb
+//	    source: client_ip
+//	    fields:
+//	      - name: asn
+//	        type: int
+type GeoIPConfig struct {
+	// Path to the MaxMind DB file.
+	Path string `yaml:"path"`
+
+	// Record field with the IP address.
+	Source string `yaml:"source"`
+
+	// Fields added to the record.
+	// Field source is the dot-separated path in the database record, e.g. "country.iso_code"
+	// or "subdivisions.0.names.en". Default is the field name or the predefined path for
+	// "country", "continent", "city", "region", "postal_code", "latitude", "longitude",
+	// "timezone", "asn", and "as_org".
+	Fields []*field.Field `yaml:"fields"`
+
+	// Number of IP addresses to keep in the cache.
+	// Default: 10000
+	CacheSize int `yaml:"cache_size,omitempty"`
+
+	fields []*field.Field
+
+	mutex  sync.RWMutex
+	reader *maxminddb.Reader
+	cache  *geoipCache
+
+	reload *fileReload
+}
+
+// geoipSources is the predefined paths for the GeoLite2 City and ASN databases.
+var geoipSources = map[string]string{
+	"country":     "country.iso_code",
+	"continent":   "continent.code",
+	"city":        "city.names.en",
+	"region":      "subdivisions.0.iso_code",
+	"postal_code": "postal.code",
+	"latitude":    "location.latitude",
+	"longitude":   "location.longitude",
+	"timezone":    "location.time_zone",
+	"asn":         "autonomous_system_number",
+	"as_org":      "autonomous_system_organization",
+}
+
+func (gc *GeoIPConfig) Init(fields []*field.Field) error {
+	if gc.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	if gc.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+
+	if !slices.ContainsFunc(fields, func(f *field.Field) bool { return f.Name == gc.Source }) {
+		return fmt.Errorf("unknown source field %s", gc.Source)
+	}
+
+	if len(gc.Fields) == 0 {
+		return fmt.Errorf("fields are required")
+	}
+
+	if gc.CacheSize == 0 {
+		gc.CacheSize = 10000
+	} else if gc.CacheSize < 0 {
+		return fmt.Errorf("cache size should be positive")
+	}
+
+	gc.fields = make([]*field.Field, len(gc.Fields))
+	for i := range gc.Fields {
+		f := gc.Fields[i].Clone()
+		if f.Source == "" {
+			f.Source = geoipSources[f.Name]
+		}
+		if err := f.Init(); err != nil {
+			return fmt.Errorf("field %s init: %w", f.Name, err)
+		}
+		gc.fields[i] = f
+	}
+
+	return gc.load()
+}
+
+func (gc *GeoIPConfig) Start() {
+	gc.reload = newFileReload(gc.Path, gc.load)
+	gc.reload.Start()
+}
+
+func (gc *GeoIPConfig) Stop() {
+	if gc.reload != nil {
+		gc.reload.Stop()
+	}
+
+	gc.mutex.Lock()
+	defer gc.mutex.Unlock()
+
+	if gc.reader != nil {
+		gc.reader.Close()
+		gc.reader = nil
+	}
+}
+
+// load opens the database file and replaces the current one.
+// The current database is kept if the file could not be opened.
+func (gc *GeoIPConfig) load() error {
+	reader, err := maxminddb.Open(gc.Path)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+
+	gc.mutex.Lock()
+	prev := gc.reader
+	gc.reader = reader
+	gc.cache = newGeoipCache(gc.CacheSize)
+	gc.mutex.Unlock()
+
+	// Lookups hold the read lock, so the previous reader is not used anymore
+	if prev != nil {
+		prev.Close()
+	}
+
+	return nil
+}
+
+// enrich adds the fields of the IP address to the record.
+// Fields get the default value if the address is not found.
+func (gc *GeoIPConfig) enrich(record map[string]any) {
+	values := gc.lookup(record[gc.Source])
+
+	for _, f := range gc.fields {
+		if val, ok := values[f.Name]; ok {
+			record[f.Name] = val
+		} else {
+			record[f.Name] = f.Default()
+		}
+	}
+}
+
+func (gc *GeoIPConfig) lookup(source any) map[string]any {
+	str, ok := source.(string)
+	if !ok {
+		return nil
+	}
+
+	addr, err := netip.ParseAddr(str)
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap().WithZone("")
+	key := addr.String()
+
+	gc.mutex.RLock()
+	defer gc.mutex.RUnlock()
+
+	if gc.reader == nil {
+		return nil
+	}
+
+	if values, ok := gc.cache.get(key); ok {
+		return values
+	}
+
+	var data any
+	if err := gc.reader.Lookup(net.IP(addr.AsSlice()), &data); err != nil {
+		// IPv6 address in the IPv4 database
+		data = nil
+	}
+
+	var values map[string]any
+	if data != nil {
+		values = gc.convert(data)
+	}
+	gc.cache.add(key, values)
+
+	return values
+}
+
+// convert extracts the fields from the database record.
+func (gc *GeoIPConfig) convert(data any) map[string]any {
+	source := make(map[string]string, len(gc.fields))
+	for _, f := range gc.fields {
+		if val, ok := geoipValue(data, f.Source); ok {
+			source[f.Source] = val
+		}
+	}
+
+	values := make(map[string]any, len(gc.fields))
+	for _, f := range gc.fields {
+		if val, err := f.Convert(source); err == nil && val != nil {
+			values[f.Name] = val
+		}
+	}
+
+	return values
+}
+
+// geoipValue returns the value by the dot-separated path, e.g. "subdivisions.0.iso_code".
+func geoipValue(data any, path string) (string, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]any:
+			data = node[key]
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return "", false
+			}
+			data = node[idx]
+		default:
+			return "", false
+		}
+	}
+
+	if data == nil {
+		return "", false
+	}
+
+	return lookupString(data), true
+}
+
+// geoipCache is the LRU cache of the looked-up fields by the IP address.
+type geoipCache struct {
+	mutex sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+type geoipCacheItem struct {
+	key    string
+	values map[string]any
+}
+
+func newGeoipCache(size int) *geoipCache {
+	return &geoipCache{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func (c *geoipCache) get(key string) (map[string]any, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if item, ok := c.items[key]; ok {
+		c.order.MoveToFront(item)
+		return item.Value.(*geoipCacheItem).values, true
+	}
+
+	return nil, false
+}
+
+func (c *geoipCache) add(key string, values map[string]any) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if item, ok := c.items[key]; ok {
+		c.order.MoveToFront(item)
+		item.Value.(*geoipCacheItem).values = values
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&geoipCacheItem{key, values})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*geoipCacheItem).key)
+	}
+}
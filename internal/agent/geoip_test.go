@@ -0,0 +1,373 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/stretchr/testify/require"
+)
+
+// testGeoIP_Writer builds the minimal MaxMind DB file for IPv4 addresses.
+// Format: https://maxmind.github.io/MaxMind-DB/
+type testGeoIP_Writer struct {
+	nodes [][2]int // -1 is empty record, -2-N is data record N
+	data  bytes.Buffer
+	items []int // offsets of data records
+}
+
+func newTestGeoIP_Writer() *testGeoIP_Writer {
+	return &testGeoIP_Writer{nodes: [][2]int{{-1, -1}}}
+}
+
+func (w *testGeoIP_Writer) Insert(prefix string, record map[string]any) {
+	p := netip.MustParsePrefix(prefix)
+	ip := p.Addr().As4()
+
+	w.items = append(w.items, w.data.Len())
+	testGeoIP_Encode(&w.data, record)
+	leaf := -2 - (len(w.items) - 1)
+
+	node := 0
+	for i := range p.Bits() {
+		bit := (ip[i/8] >> (7 - i%8)) & 1
+		if i == p.Bits()-1 {
+			w.nodes[node][bit] = leaf
+			break
+		}
+		if w.nodes[node][bit] < 0 {
+			w.nodes = append(w.nodes, [2]int{-1, -1})
+			w.nodes[node][bit] = len(w.nodes) - 1
+		}
+		node = w.nodes[node][bit]
+	}
+}
+
+func (w *testGeoIP_Writer) Write(t *testing.T, path string) {
+	var buf bytes.Buffer
+
+	count := len(w.nodes)
+	for _, node := range w.nodes {
+		for _, record := range node {
+			value := record
+			switch {
+			case record == -1:
+				value = count
+			case record < -1:
+				value = count + 16 + w.items[-2-record]
+			}
+			buf.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+
+	buf.Write(make([]byte, 16))
+	buf.Write(w.data.Bytes())
+	buf.WriteString("\xab\xcd\xefMaxMind.com")
+	testGeoIP_Encode(&buf, map[string]any{
+		"node_count":                  uint32(count),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               "Test-City",
+		"languages":                   []any{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1672574400),
+		"description":                 map[string]any{"en": "Test database"},
+	})
+
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func testGeoIP_Control(buf *bytes.Buffer, kind int, size int) {
+	var ctrl byte
+	if kind > 7 {
+		ctrl = 0
+	} else {
+		ctrl = byte(kind) << 5
+	}
+
+	var ext []byte
+	switch {
+	case size < 29:
+		ctrl |= byte(size)
+	case size < 285:
+		ctrl |= 29
+		ext = []byte{byte(size - 29)}
+	default:
+		ctrl |= 30
+		ext = binary.BigEndian.AppendUint16(nil, uint16(size-285))
+	}
+
+	buf.WriteByte(ctrl)
+	if kind > 7 {
+		buf.WriteByte(byte(kind - 7))
+	}
+	buf.Write(ext)
+}
+
+func testGeoIP_Encode(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case string:
+		testGeoIP_Control(buf, 2, len(v))
+		buf.WriteString(v)
+	case float64:
+		testGeoIP_Control(buf, 3, 8)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case uint16:
+		testGeoIP_Control(buf, 5, 2)
+		buf.Write(binary.BigEndian.AppendUint16(nil, v))
+	case uint32:
+		testGeoIP_Control(buf, 6, 4)
+		buf.Write(binary.BigEndian.AppendUint32(nil, v))
+	case uint64:
+		testGeoIP_Control(buf, 9, 8)
+		buf.Write(binary.BigEndian.AppendUint64(nil, v))
+	case map[string]any:
+		testGeoIP_Control(buf, 7, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			testGeoIP_Encode(buf, key)
+			testGeoIP_Encode(buf, v[key])
+		}
+	case []any:
+		testGeoIP_Control(buf, 11, len(v))
+		for _, item := range v {
+			testGeoIP_Encode(buf, item)
+		}
+	default:
+		panic("unsupported type")
+	}
+}
+
+func testGeoIP_Database(t *testing.T, path string, city string) {
+	w := newTestGeoIP_Writer()
+	w.Insert("81.2.69.0/24", map[string]any{
+		"city":         map[string]any{"names": map[string]any{"en": city, "de": "London"}},
+		"country":      map[string]any{"iso_code": "GB"},
+		"location":     map[string]any{"latitude": 51.5142, "longitude": -0.0931},
+		"subdivisions": []any{map[string]any{"iso_code": "ENG"}},
+	})
+	w.Insert("1.1.1.0/24", map[string]any{
+		"autonomous_system_number":       uint32(13335),
+		"autonomous_system_organization": "CLOUDFLARENET",
+	})
+	w.Write(t, path)
+}
+
+func TestGeoIPConfig_enrich(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	testGeoIP_Database(t, path, "London")
+
+	geoip := &GeoIPConfig{
+		Path:   path,
+		Source: "client_ip",
+		Fields: []*field.Field{
+			{Name: "country"},
+			{Name: "city"},
+			{Name: "city_de", Source: "city.names.de"},
+			{Name: "region"},
+			{Name: "latitude", Type: "float"},
+			{Name: "asn", Type: "int"},
+			{Name: "as_org"},
+		},
+		CacheSize: 2,
+	}
+	require.NoError(t, geoip.Init([]*field.Field{{Name: "client_ip", Type: "ip"}}))
+	defer geoip.Stop()
+
+	tests := []struct {
+		name string
+		ip   any
+		want map[string]any
+	}{
+		{
+			name: "city",
+			ip:   "81.2.69.160",
+			want: map[string]any{
+				"country":   "GB",
+				"city":      "London",
+				"city_de":   "London",
+				"region":    "ENG",
+				"latitude":  51.5142,
+				"asn":       int64(0),
+				"as_org":    "",
+				"client_ip": "81.2.69.160",
+			},
+		},
+		{
+			name: "asn",
+			ip:   "1.1.1.1",
+			want: map[string]any{
+				"country":   "",
+				"city":      "",
+				"city_de":   "",
+				"region":    "",
+				"latitude":  float64(0),
+				"asn":       int64(13335),
+				"as_org":    "CLOUDFLARENET",
+				"client_ip": "1.1.1.1",
+			},
+		},
+		{
+			name: "not found",
+			ip:   "10.0.0.1",
+			want: map[string]any{
+				"country":   "",
+				"city":      "",
+				"city_de":   "",
+				"region":    "",
+				"latitude":  float64(0),
+				"asn":       int64(0),
+				"as_org":    "",
+				"client_ip": "10.0.0.1",
+			},
+		},
+		{
+			name: "ipv6 in ipv4 database",
+			ip:   "2001:db8::1",
+			want: map[string]any{
+				"country":   "",
+				"city":      "",
+				"city_de":   "",
+				"region":    "",
+				"latitude":  float64(0),
+				"asn":       int64(0),
+				"as_org":    "",
+				"client_ip": "2001:db8::1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := map[string]any{"client_ip": tt.ip}
+			geoip.enrich(record)
+			require.Equal(t, tt.want, record)
+		})
+	}
+
+	// Cache keeps the recently used addresses only
+	require.Equal(t, 2, geoip.cache.order.Len())
+	_, ok := geoip.cache.get("81.2.69.160")
+	require.False(t, ok)
+	_, ok = geoip.cache.get("10.0.0.1")
+	require.True(t, ok)
+}
+
+func TestGeoIPConfig_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	testGeoIP_Database(t, path, "London")
+
+	geoip := &GeoIPConfig{
+		Path:   path,
+		Source: "client_ip",
+		Fields: []*field.Field{{Name: "city"}},
+	}
+	require.NoError(t, geoip.Init([]*field.Field{{Name: "client_ip", Type: "ip"}}))
+
+	geoip.Start()
+	defer geoip.Stop()
+
+	record := map[string]any{"client_ip": "81.2.69.1"}
+	geoip.enrich(record)
+	require.Equal(t, "London", record["city"])
+
+	// Replace the file with rename as database updaters do
+	tmp := path + ".tmp"
+	testGeoIP_Database(t, tmp, "City of London")
+	require.NoError(t, os.Rename(tmp, path))
+
+	require.Eventually(t, func() bool {
+		geoip.enrich(record)
+		return record["city"] == "City of London"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Invalid database keeps the previous one
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o644))
+	require.Error(t, geoip.load())
+
+	geoip.enrich(record)
+	require.Equal(t, "City of London", record["city"])
+}
+
+func TestGeoIPConfig_Init(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	testGeoIP_Database(t, path, "London")
+
+	fields := []*field.Field{{Name: "client_ip", Type: "ip"}}
+
+	tests := []struct {
+		name  string
+		geoip *GeoIPConfig
+		err   string
+	}{
+		{"no path", &GeoIPConfig{}, "path is required"},
+		{"no source", &GeoIPConfig{Path: path}, "source is required"},
+		{"unknown source", &GeoIPConfig{Path: path, Source: "ip"}, "unknown source field ip"},
+		{"no fields", &GeoIPConfig{Path: path, Source: "client_ip"}, "fields are required"},
+		{
+			"negative cache size",
+			&GeoIPConfig{Path: path, Source: "client_ip", Fields: []*field.Field{{Name: "city"}}, CacheSize: -1},
+			"cache size should be positive",
+		},
+		{
+			"missing file",
+			&GeoIPConfig{Path: path + ".old", Source: "client_ip", Fields: []*field.Field{{Name: "city"}}},
+			"open database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.geoip.Init(fields), tt.err)
+		})
+	}
+}
+
+func TestAgent_Serialize_GeoIP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	testGeoIP_Database(t, path, "London")
+
+	a := &Agent{
+		Fields: []*field.Field{
+			{Name: "time", Type: "time"},
+			{Name: "client_ip", Type: "ip"},
+		},
+		GeoIP: []*GeoIPConfig{
+			{
+				Path:   path,
+				Source: "client_ip",
+				Fields: []*field.Field{{Name: "country"}},
+			},
+		},
+		Drop: []string{`country == "GB"`},
+	}
+	ts := &testStorage{}
+	require.NoError(t, a.Init("test", &testApp{ts}))
+	defer a.GeoIP[0].Stop()
+
+	result, err := a.Serialize(map[string]string{"time": "1", "client_ip": "::ffff:1.1.1.1"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"time": int64(1), "client_ip": "1.1.1.1", "country": ""}, result)
+	a.Write(result)
+
+	// Record is dropped by the enriched field
+	result, err = a.Serialize(map[string]string{"time": "2", "client_ip": "81.2.69.1"})
+	require.NoError(t, err)
+	require.Equal(t, "GB", result["country"])
+	a.Write(result)
+
+	require.Len(t, ts.rows["test"], 1)
+	require.Equal(t, int64(1), a.GetStats()["dropped"])
+}
@@ -0,0 +1,315 @@
+package agent
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+// LookupConfig enriches records with columns from the table file.
+// The table is reloaded when the file changes.
+//
+//	lookup:
+//	  - path: /etc/fugo/hosts.csv
+//	    key: host
+//	    fields:
+//	      - name: datacenter
+//	      - name: owner
+//	    default:
+//	      datacenter: unknown
+type LookupConfig struct {
+	// Path to the table file: CSV with header, YAML, or JSON.
+	// YAML and JSON tables are maps from the key to the columns or to the single value:
+	//
+	//	web-1: { datacenter: fra1, owner: web-team }
+	//	"404": Not Found
+	//
+	// The single value is available as the "value" column.
+	// Tables could be also lists of objects with the key column.
+	Path string `yaml:"path"`
+
+	// Record field with the lookup key.
+	Key string `yaml:"key"`
+
+	// Table column with the key.
+	// Default: first column for CSV, required for lists in YAML and JSON.
+	Column string `yaml:"column,omitempty"`
+
+	// Fields added to the record.
+	// Field source is the table column, default is the field name.
+	Fields []*field.Field `yaml:"fields"`
+
+	// Values of the table columns used if the key is not found.
+	// Fields without default get the default value of the field type.
+	Default map[string]string `yaml:"default,omitempty"`
+
+	fields   []*field.Field
+	defaults map[string]any
+
+	mutex sync.RWMutex
+	table map[string]map[string]any
+
+	reload *fileReload
+}
+
+// lookupValue is the column name for the single value in YAML and JSON tables.
+const lookupValue = "value"
+
+func (lc *LookupConfig) Init(fields []*field.Field) error {
+	if lc.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	lc.Path = filepath.Clean(lc.Path)
+
+	if lc.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	if !slices.ContainsFunc(fields, func(f *field.Field) bool { return f.Name == lc.Key }) {
+		return fmt.Errorf("unknown key field %s", lc.Key)
+	}
+
+	if len(lc.Fields) == 0 {
+		return fmt.Errorf("fields are required")
+	}
+
+	lc.fields = make([]*field.Field, len(lc.Fields))
+	for i := range lc.Fields {
+		f := lc.Fields[i].Clone()
+		if err := f.Init(); err != nil {
+			return fmt.Errorf("field %s init: %w", f.Name, err)
+		}
+		lc.fields[i] = f
+	}
+
+	lc.defaults = make(map[string]any, len(lc.fields))
+	for _, f := range lc.fields {
+		val, err := f.Convert(lc.Default)
+		if err != nil {
+			return fmt.Errorf("field %s default: %w", f.Name, err)
+		}
+		if val == nil {
+			val = f.Default()
+		}
+		lc.defaults[f.Name] = val
+	}
+
+	return lc.load()
+}
+
+func (lc *LookupConfig) Start() {
+	lc.reload = newFileReload(lc.Path, lc.load)
+	lc.reload.Start()
+}
+
+func (lc *LookupConfig) Stop() {
+	if lc.reload != nil {
+		lc.reload.Stop()
+	}
+}
+
+// load reads the table file and replaces the current table.
+// The current table is kept if the file could not be loaded.
+func (lc *LookupConfig) load() error {
+	var rows map[string]map[string]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(lc.Path)) {
+	case ".csv":
+		rows, err = lc.readCSV()
+	case ".yaml", ".yml", ".json":
+		rows, err = lc.readYAML()
+	default:
+		return fmt.Errorf("unsupported table format: %s", lc.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	table := make(map[string]map[string]any, len(rows))
+	for key, row := range rows {
+		// Empty cells are misses
+		maps.DeleteFunc(row, func(_, value string) bool { return value == "" })
+
+		record := make(map[string]any, len(lc.fields))
+		for _, f := range lc.fields {
+			val, err := f.Convert(row)
+			if err != nil {
+				return fmt.Errorf("key %s: field %s: %w", key, f.Name, err)
+			}
+			if val == nil {
+				val = lc.defaults[f.Name]
+			}
+			record[f.Name] = val
+		}
+		table[key] = record
+	}
+
+	lc.mutex.Lock()
+	lc.table = table
+	lc.mutex.Unlock()
+
+	return nil
+}
+
+func (lc *LookupConfig) readCSV() (map[string]map[string]string, error) {
+	file, err := os.Open(lc.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("header is required")
+	}
+
+	header := lines[0]
+	column := 0
+	if lc.Column != "" {
+		column = -1
+		for i, name := range header {
+			if name == lc.Column {
+				column = i
+				break
+			}
+		}
+		if column == -1 {
+			return nil, fmt.Errorf("unknown key column %s", lc.Column)
+		}
+	}
+
+	rows := make(map[string]map[string]string, len(lines)-1)
+	for _, line := range lines[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[name] = line[i]
+		}
+		rows[line[column]] = row
+	}
+
+	return rows, nil
+}
+
+func (lc *LookupConfig) readYAML() (map[string]map[string]string, error) {
+	data, err := os.ReadFile(lc.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is a subset of YAML
+	var content any
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid table: %w", err)
+	}
+
+	rows := make(map[string]map[string]string)
+
+	if list, ok := content.([]any); ok {
+		if lc.Column == "" {
+			return nil, fmt.Errorf("column is required for the list of objects")
+		}
+		for i, value := range list {
+			row, ok := lookupRow(value)
+			if !ok {
+				return nil, fmt.Errorf("item #%d should be an object", i+1)
+			}
+			if key, ok := row[lc.Column]; ok {
+				rows[key] = row
+			}
+		}
+	} else if content != nil {
+		table := lookupMap(content)
+		if table == nil {
+			return nil, fmt.Errorf("table should be a map or a list of objects")
+		}
+		for key, value := range table {
+			if row, ok := lookupRow(value); ok {
+				rows[key] = row
+			} else {
+				rows[key] = map[string]string{lookupValue: lookupString(value)}
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// lookupMap returns the YAML mapping with keys converted to strings, e.g. status codes.
+func lookupMap(value any) map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[lookupString(key)] = item
+		}
+		return result
+	}
+
+	return nil
+}
+
+func lookupRow(value any) (map[string]string, bool) {
+	columns := lookupMap(value)
+	if columns == nil {
+		return nil, false
+	}
+
+	row := make(map[string]string, len(columns))
+	for name, value := range columns {
+		row[name] = lookupString(value)
+	}
+	return row, true
+}
+
+// lookupString converts the column or key value to string.
+// Nested objects and lists are converted to JSON.
+func lookupString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// enrich adds the looked-up columns or defaults to the record.
+func (lc *LookupConfig) enrich(record map[string]any) {
+	row := lc.defaults
+
+	if key := record[lc.Key]; key != nil {
+		lc.mutex.RLock()
+		if found, ok := lc.table[lookupString(key)]; ok {
+			row = found
+		}
+		lc.mutex.RUnlock()
+	}
+
+	for name, value := range row {
+		record[name] = value
+	}
+}
@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/stretchr/testify/require"
+)
+
+var testLookup_Fields = []*field.Field{
+	{Name: "time", Type: "time"},
+	{Name: "host", Type: "string"},
+	{Name: "status", Type: "int"},
+}
+
+func testLookup_WriteFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLookupConfig_enrich(t *testing.T) {
+	dir := t.TempDir()
+
+	testLookup_WriteFile(t, filepath.Join(dir, "hosts.csv"),
+		"host,datacenter,owner,cores\n"+
+			"web-1,fra1,web-team,8\n"+
+			"db-1,ams3,db-team,\n")
+
+	testLookup_WriteFile(t, filepath.Join(dir, "status.yaml"),
+		"200: OK\n"+
+			"404: Not Found\n")
+
+	testLookup_WriteFile(t, filepath.Join(dir, "hosts.json"),
+		`[{"name": "web-1", "rack": 12}, {"name": "db-1", "rack": 7}]`)
+
+	tests := []struct {
+		name   string
+		lookup *LookupConfig
+		record map[string]any
+		want   map[string]any
+	}{
+		{
+			name: "csv",
+			lookup: &LookupConfig{
+				Path: filepath.Join(dir, "hosts.csv"),
+				Key:  "host",
+				Fields: []*field.Field{
+					{Name: "datacenter"},
+					{Name: "team", Source: "owner"},
+					{Name: "cores", Type: "int"},
+				},
+			},
+			record: map[string]any{"host": "web-1"},
+			want: map[string]any{
+				"host":       "web-1",
+				"datacenter": "fra1",
+				"team":       "web-team",
+				"cores":      int64(8),
+			},
+		},
+		{
+			name: "csv empty value",
+			lookup: &LookupConfig{
+				Path:    filepath.Join(dir, "hosts.csv"),
+				Key:     "host",
+				Fields:  []*field.Field{{Name: "cores", Type: "int"}},
+				Default: map[string]string{"cores": "1"},
+			},
+			record: map[string]any{"host": "db-1"},
+			want:   map[string]any{"host": "db-1", "cores": int64(1)},
+		},
+		{
+			name: "csv miss with defaults",
+			lookup: &LookupConfig{
+				Path: filepath.Join(dir, "hosts.csv"),
+				Key:  "host",
+				Fields: []*field.Field{
+					{Name: "datacenter"},
+					{Name: "cores", Type: "int"},
+				},
+				Default: map[string]string{"datacenter": "unknown"},
+			},
+			record: map[string]any{"host": "cache-1"},
+			want: map[string]any{
+				"host":       "cache-1",
+				"datacenter": "unknown",
+				"cores":      int64(0),
+			},
+		},
+		{
+			name: "yaml with int key",
+			lookup: &LookupConfig{
+				Path:   filepath.Join(dir, "status.yaml"),
+				Key:    "status",
+				Fields: []*field.Field{{Name: "status_text", Source: "value"}},
+			},
+			record: map[string]any{"status": int64(404)},
+			want:   map[string]any{"status": int64(404), "status_text": "Not Found"},
+		},
+		{
+			name: "json list with key column",
+			lookup: &LookupConfig{
+				Path:   filepath.Join(dir, "hosts.json"),
+				Key:    "host",
+				Column: "name",
+				Fields: []*field.Field{{Name: "rack", Type: "int"}},
+			},
+			record: map[string]any{"host": "db-1"},
+			want:   map[string]any{"host": "db-1", "rack": int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.lookup.Init(testLookup_Fields))
+
+			tt.lookup.enrich(tt.record)
+			require.Equal(t, tt.want, tt.record)
+		})
+	}
+}
+
+func TestLookupConfig_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.csv")
+	testLookup_WriteFile(t, path, "host,datacenter\nweb-1,fra1\n")
+
+	lookup := &LookupConfig{
+		Path:   path,
+		Key:    "host",
+		Fields: []*field.Field{{Name: "datacenter"}},
+	}
+	require.NoError(t, lookup.Init(testLookup_Fields))
+
+	lookup.Start()
+	defer lookup.Stop()
+
+	// Replace the file with rename as configuration tools do
+	tmp := path + ".tmp"
+	testLookup_WriteFile(t, tmp, "host,datacenter\nweb-1,ams3\n")
+	require.NoError(t, os.Rename(tmp, path))
+
+	require.Eventually(t, func() bool {
+		record := map[string]any{"host": "web-1"}
+		lookup.enrich(record)
+		return record["datacenter"] == "ams3"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Invalid table keeps the previous one
+	testLookup_WriteFile(t, path, "host,datacenter\nweb-1\n")
+	require.Error(t, lookup.load())
+
+	record := map[string]any{"host": "web-1"}
+	lookup.enrich(record)
+	require.Equal(t, "ams3", record["datacenter"])
+}
+
+func TestLookupConfig_Init(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	testLookup_WriteFile(t, path, "- host: web-1\n")
+
+	tests := []struct {
+		name   string
+		lookup *LookupConfig
+		err    string
+	}{
+		{"no path", &LookupConfig{}, "path is required"},
+		{"unknown key", &LookupConfig{Path: path, Key: "ip"}, "unknown key field ip"},
+		{"no fields", &LookupConfig{Path: path, Key: "host"}, "fields are required"},
+		{
+			"list without column",
+			&LookupConfig{Path: path, Key: "host", Fields: []*field.Field{{Name: "owner"}}},
+			"column is required for the list of objects",
+		},
+		{
+			"unsupported format",
+			&LookupConfig{Path: path + ".txt", Key: "host", Fields: []*field.Field{{Name: "owner"}}},
+			"unsupported table format",
+		},
+		{
+			"missing file",
+			&LookupConfig{Path: path + ".csv", Key: "host", Fields: []*field.Field{{Name: "owner"}}},
+			"no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.lookup.Init(testLookup_Fields), tt.err)
+		})
+	}
+}
+
+func TestAgent_Serialize_Lookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.csv")
+	testLookup_WriteFile(t, path, "host,datacenter\nweb-1,fra1\n")
+
+	a := &Agent{
+		Fields: []*field.Field{
+			{Name: "time", Type: "time"},
+			{Name: "host"},
+		},
+		Lookup: []*LookupConfig{
+			{
+				Path:   path,
+				Key:    "host",
+				Fields: []*field.Field{{Name: "datacenter"}},
+			},
+		},
+		Filter: []string{`datacenter == "fra1"`},
+	}
+	require.NoError(t, a.Init("test", &testApp{&testStorage{}}))
+	require.Equal(t, "datacenter", a.GetFields()[2].Name)
+
+	result, err := a.Serialize(map[string]string{"time": "1", "host": "web-1", "datacenter": "raw"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"time": int64(1), "host": "web-1", "datacenter": "fra1"}, result)
+
+	a.Lookup[0].Fields[0].Name = "host"
+	require.ErrorContains(t, a.Init("test", &testApp{&testStorage{}}), "lookup #1: field host is already defined")
+}
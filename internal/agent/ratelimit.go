@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/internal/storage"
+	"github.com/fugo-app/fugo/pkg/duration"
+)
+
+// RateLimitConfig limits the number of records written per second with the token bucket.
+// Number of dropped records is periodically stored in the separate table named "<agent>__rate_limit".
+type RateLimitConfig struct {
+	// Maximum number of records per second.
+	Rate float64 `yaml:"rate"`
+
+	// Maximum number of records written at once after idle period.
+	// Default: rate rounded up
+	Burst int `yaml:"burst,omitempty"`
+
+	// Interval to write the summary of dropped records. Default is 60s
+	Interval string `yaml:"interval,omitempty"`
+
+	// Retention configuration for the summary table.
+	Retention storage.RetentionConfig `yaml:"retention,omitempty"`
+
+	name     string
+	storage  storage.StorageDriver
+	interval time.Duration
+
+	mutex   sync.Mutex
+	tokens  float64
+	last    time.Time
+	dropped int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+const rateLimitSuffix = "__rate_limit"
+
+var rateLimitFields = []*field.Field{
+	{
+		Name:        "time",
+		Type:        "time",
+		Description: "Time of the summary",
+	},
+	{
+		Name:        "dropped",
+		Type:        "int",
+		Description: "Number of records dropped since the previous summary",
+	},
+}
+
+// Current time, replaced in tests.
+var stdRateLimitNow = time.Now
+
+func (rl *RateLimitConfig) Init(name string, storage storage.StorageDriver) error {
+	rl.name = name + rateLimitSuffix
+	rl.storage = storage
+
+	if rl.Rate <= 0 {
+		return fmt.Errorf("rate should be positive")
+	}
+
+	if rl.Burst == 0 {
+		rl.Burst = int(math.Ceil(rl.Rate))
+	} else if rl.Burst < 0 {
+		return fmt.Errorf("burst should be positive")
+	}
+
+	rl.interval = 60 * time.Second // Default to 60 seconds
+	if rl.Interval != "" {
+		d, err := duration.Parse(rl.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("interval should be positive")
+		}
+		rl.interval = d
+	}
+
+	rl.tokens = float64(rl.Burst)
+	rl.last = stdRateLimitNow()
+
+	fields := make([]*field.Field, len(rateLimitFields))
+	for i := range rateLimitFields {
+		fields[i] = rateLimitFields[i].Clone()
+		if err := fields[i].Init(); err != nil {
+			return fmt.Errorf("field %s init: %w", fields[i].Name, err)
+		}
+	}
+
+	if err := rl.Retention.Init(rl.name, "time", storage); err != nil {
+		return fmt.Errorf("retention init: %w", err)
+	}
+
+	if err := storage.Migrate(rl.name, fields); err != nil {
+		return fmt.Errorf("migrate table (%s): %w", rl.name, err)
+	}
+
+	return nil
+}
+
+func (rl *RateLimitConfig) Start() {
+	rl.stop = make(chan struct{})
+	rl.done = make(chan struct{})
+	go rl.run(rl.stop, rl.done)
+
+	rl.Retention.Start()
+}
+
+// Stop waits for the final summary to be written.
+func (rl *RateLimitConfig) Stop() {
+	if rl.stop != nil {
+		close(rl.stop)
+		<-rl.done
+		rl.stop = nil
+	}
+
+	rl.Retention.Stop()
+}
+
+func (rl *RateLimitConfig) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(rl.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rl.summary()
+		case <-stop:
+			// Records dropped since the last tick
+			rl.summary()
+			return
+		}
+	}
+}
+
+// summary writes the number of dropped records if any.
+func (rl *RateLimitConfig) summary() {
+	rl.mutex.Lock()
+	dropped := rl.dropped
+	rl.dropped = 0
+	rl.mutex.Unlock()
+
+	if dropped == 0 {
+		return
+	}
+
+	log.Printf("Records dropped by rate limit (%s): %d\n", rl.name, dropped)
+
+	rl.storage.Write(rl.name, map[string]any{
+		"time":    stdRateLimitNow().UnixMilli(),
+		"dropped": dropped,
+	})
+}
+
+// allow takes a token from the bucket and returns false if the record should be dropped.
+func (rl *RateLimitConfig) allow() bool {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := stdRateLimitNow()
+	if elapsed := now.Sub(rl.last).Seconds(); elapsed > 0 {
+		rl.tokens = math.Min(rl.tokens+elapsed*rl.Rate, float64(rl.Burst))
+	}
+	rl.last = now
+
+	if rl.tokens < 1 {
+		rl.dropped++
+		return false
+	}
+
+	rl.tokens--
+
+	return true
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type testStorage struct {
+	storage.DummyStorage
+
+	rows map[string][]map[string]any
+}
+
+func (ts *testStorage) Write(name string, data map[string]any) {
+	if ts.rows == nil {
+		ts.rows = make(map[string][]map[string]any)
+	}
+	ts.rows[name] = append(ts.rows[name], data)
+}
+
+func TestRateLimitConfig_allow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stdNow := stdRateLimitNow
+	stdRateLimitNow = func() time.Time { return now }
+	t.Cleanup(func() { stdRateLimitNow = stdNow })
+
+	ts := &testStorage{}
+	rl := &RateLimitConfig{Rate: 2, Burst: 3}
+	require.NoError(t, rl.Init("test", ts))
+
+	// Burst is available at once
+	for range 3 {
+		require.True(t, rl.allow())
+	}
+	require.False(t, rl.allow())
+
+	// Half a second refills one token
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, rl.allow())
+	require.False(t, rl.allow())
+
+	// Bucket is not filled above the burst
+	now = now.Add(time.Minute)
+	for range 3 {
+		require.True(t, rl.allow())
+	}
+	require.False(t, rl.allow())
+
+	rl.summary()
+	require.Equal(t, []map[string]any{
+		{"time": now.UnixMilli(), "dropped": int64(3)},
+	}, ts.rows["test__rate_limit"])
+
+	// Nothing dropped since the previous summary
+	rl.summary()
+	require.Len(t, ts.rows["test__rate_limit"], 1)
+}
+
+func TestRateLimitConfig_Stop(t *testing.T) {
+	ts := &testStorage{}
+	rl := &RateLimitConfig{Rate: 1, Burst: 1, Interval: "1h"}
+	require.NoError(t, rl.Init("test", ts))
+
+	rl.Start()
+
+	require.True(t, rl.allow())
+	require.False(t, rl.allow())
+
+	// Final summary is written before the interval
+	rl.Stop()
+	require.Len(t, ts.rows["test__rate_limit"], 1)
+	require.Equal(t, int64(1), ts.rows["test__rate_limit"][0]["dropped"])
+}
+
+func TestRateLimitConfig_Init(t *testing.T) {
+	rl := &RateLimitConfig{Rate: 0.5}
+	require.NoError(t, rl.Init("test", &testStorage{}))
+	require.Equal(t, 1, rl.Burst)
+
+	rl = &RateLimitConfig{}
+	require.ErrorContains(t, rl.Init("test", &testStorage{}), "rate should be positive")
+
+	rl = &RateLimitConfig{Rate: 1, Interval: "0s"}
+	require.ErrorContains(t, rl.Init("test", &testStorage{}), "interval should be positive")
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/fugo-app/fugo/pkg/debounce"
+)
+
+// fileReload calls the load function when the file changes.
+// Errors are logged and the previous state is kept by the load function.
+type fileReload struct {
+	path   string
+	load   func() error
+	reload *debounce.Debounce
+	stop   chan struct{}
+}
+
+func newFileReload(path string, load func() error) *fileReload {
+	return &fileReload{
+		path: filepath.Clean(path),
+		load: load,
+	}
+}
+
+func (fr *fileReload) Start() {
+	fr.stop = make(chan struct{})
+	fr.reload = debounce.NewDebounce(func() {
+		if err := fr.load(); err != nil {
+			log.Printf("failed to reload file (%s): %v", fr.path, err)
+		}
+	}, time.Second, false)
+	fr.reload.Start()
+
+	// Directory is watched to handle the file replaced with rename
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("failed to start watcher (%s): %v", fr.path, err)
+		return
+	}
+
+	if err := watcher.Add(filepath.Dir(fr.path)); err != nil {
+		log.Printf("failed to watch file (%s): %v", fr.path, err)
+		watcher.Close()
+		return
+	}
+
+	go fr.watch(watcher, fr.stop)
+}
+
+func (fr *fileReload) Stop() {
+	if fr.stop != nil {
+		close(fr.stop)
+		fr.stop = nil
+	}
+
+	fr.reload.Stop()
+}
+
+func (fr *fileReload) watch(watcher *fsnotify.Watcher, stop chan struct{}) {
+	defer watcher.Close()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Clean(event.Name) == fr.path &&
+				(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				fr.reload.Emit()
+			}
+		}
+	}
+}
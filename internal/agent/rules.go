@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/pkg/expr"
+)
+
+// rawPrefix is a prefix for identifiers to get values from the raw parsed data
+// before conversion, e.g. "raw.status".
+const rawPrefix = "raw."
+
+// rule is a compiled filter or drop expression.
+type rule struct {
+	expr *expr.Expr
+	drop bool
+
+	// Expression uses raw values and should be evaluated in Serialize.
+	raw bool
+}
+
+// recordRules is a list of rules to filter records.
+type recordRules []*rule
+
+// compileExpr compiles the expression and checks that identifiers are names of the agent fields
+// or raw values with the "raw." prefix if allowed. Returns true if the expression uses raw values.
+func compileExpr(source string, fields []*field.Field, allowRaw bool) (*expr.Expr, bool, error) {
+	e, err := expr.Compile(source)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid expression (%s): %w", source, err)
+	}
+
+	raw := false
+
+	for _, name := range e.Names() {
+		if strings.HasPrefix(name, rawPrefix) {
+			if !allowRaw {
+				return nil, false, fmt.Errorf("raw values are not available (%s)", source)
+			}
+			raw = true
+		} else if !slices.ContainsFunc(fields, func(f *field.Field) bool { return f.Name == name }) {
+			return nil, false, fmt.Errorf("unknown field %s (%s)", name, source)
+		}
+	}
+
+	return e, raw, nil
+}
+
+// recordEnv returns values of the record fields and raw values with the "raw." prefix.
+func recordEnv(record map[string]any, raw map[string]string) expr.Env {
+	return func(name string) any {
+		if key, ok := strings.CutPrefix(name, rawPrefix); ok {
+			if val, ok := raw[key]; ok {
+				return val
+			}
+			return nil
+		}
+		return record[name]
+	}
+}
+
+// newRecordRules compiles filter and drop expressions.
+func newRecordRules(filter []string, drop []string, fields []*field.Field, allowRaw bool) (recordRules, error) {
+	var rules recordRules
+
+	for _, source := range filter {
+		e, raw, err := compileExpr(source, fields, allowRaw)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+		rules = append(rules, &rule{expr: e, raw: raw})
+	}
+
+	for _, source := range drop {
+		e, raw, err := compileExpr(source, fields, allowRaw)
+		if err != nil {
+			return nil, fmt.Errorf("drop: %w", err)
+		}
+		rules = append(rules, &rule{expr: e, raw: raw, drop: true})
+	}
+
+	return rules, nil
+}
+
+// keep checks if the record passes all rules of the stage.
+// Rules with raw values are evaluated only if the raw data is defined,
+// other rules only if it is not, so each rule is evaluated once per record.
+func (rr recordRules) keep(record map[string]any, raw map[string]string) bool {
+	if len(rr) == 0 {
+		return true
+	}
+
+	env := recordEnv(record, raw)
+
+	for _, r := range rr {
+		if r.raw != (raw != nil) {
+			continue
+		}
+
+		if r.expr.Match(env) == r.drop {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/stretchr/testify/require"
+)
+
+var testRules_Fields = []*field.Field{
+	{Name: "time", Type: "time"},
+	{Name: "status", Type: "int"},
+	{Name: "path", Type: "string"},
+}
+
+func TestRecordRules_keep(t *testing.T) {
+	rules, err := newRecordRules(
+		[]string{"status >= 400"},
+		[]string{`path =~ "^/health"`, `raw.level == "debug"`},
+		testRules_Fields,
+		true,
+	)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	tests := []struct {
+		name   string
+		record map[string]any
+		raw    map[string]string
+		want   bool
+	}{
+		{
+			name:   "raw stage skips record rules",
+			record: map[string]any{"status": int64(200), "path": "/health"},
+			raw:    map[string]string{"level": "info"},
+			want:   true,
+		},
+		{
+			name:   "raw stage drops by raw value",
+			record: map[string]any{"status": int64(500), "path": "/"},
+			raw:    map[string]string{"level": "debug"},
+			want:   false,
+		},
+		{
+			name:   "filter does not match",
+			record: map[string]any{"status": int64(200), "path": "/"},
+			want:   false,
+		},
+		{
+			name:   "drop matches",
+			record: map[string]any{"status": int64(500), "path": "/health/live"},
+			want:   false,
+		},
+		{
+			name:   "record is kept",
+			record: map[string]any{"status": int64(500), "path": "/api"},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, rules.keep(tt.record, tt.raw))
+		})
+	}
+}
+
+func TestRecordRules_Error(t *testing.T) {
+	_, err := newRecordRules([]string{"status >"}, nil, testRules_Fields, true)
+	require.ErrorContains(t, err, "filter: invalid expression (status >)")
+
+	_, err = newRecordRules(nil, []string{"method == 1"}, testRules_Fields, true)
+	require.ErrorContains(t, err, "drop: unknown field method")
+
+	_, err = newRecordRules(nil, []string{"raw.method == 1"}, testRules_Fields, false)
+	require.ErrorContains(t, err, "raw values are not available")
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/pkg/expr"
+)
+
+// SamplingConfig keeps only a fraction of records.
+type SamplingConfig struct {
+	// Fraction of records to keep, from 0 to 1. Required.
+	// Example: 0.1 keeps 10% of records
+	Ratio *float64 `yaml:"ratio"`
+
+	// Field to sample deterministically by the hash of its value.
+	// Records with the same value are kept or dropped together, e.g. by the request ID.
+	// Default: records are sampled randomly
+	Key string `yaml:"key,omitempty"`
+
+	// Records matching any expression are always kept.
+	// Example: ["status >= 500"]
+	Keep []string `yaml:"keep,omitempty"`
+
+	keep      []*expr.Expr
+	ratio     float64
+	threshold uint64
+}
+
+// samplingScale is a resolution of the ratio for the hash-based sampling.
+const samplingScale = 1_000_000
+
+func (sc *SamplingConfig) Init(fields []*field.Field) error {
+	if sc.Ratio == nil {
+		return fmt.Errorf("ratio is required")
+	}
+	sc.ratio = *sc.Ratio
+	if sc.ratio < 0 || sc.ratio > 1 {
+		return fmt.Errorf("ratio should be between 0 and 1")
+	}
+	sc.threshold = uint64(sc.ratio * samplingScale)
+
+	if sc.Key != "" && !slices.ContainsFunc(fields, func(f *field.Field) bool { return f.Name == sc.Key }) {
+		return fmt.Errorf("unknown key field %s", sc.Key)
+	}
+
+	sc.keep = nil
+	for _, source := range sc.Keep {
+		e, _, err := compileExpr(source, fields, false)
+		if err != nil {
+			return fmt.Errorf("keep: %w", err)
+		}
+		sc.keep = append(sc.keep, e)
+	}
+
+	return nil
+}
+
+// sample checks if the record should be kept.
+func (sc *SamplingConfig) sample(record map[string]any) bool {
+	if len(sc.keep) > 0 {
+		env := recordEnv(record, nil)
+		for _, e := range sc.keep {
+			if e.Match(env) {
+				return true
+			}
+		}
+	}
+
+	if sc.Key == "" {
+		return rand.Float64() < sc.ratio
+	}
+
+	h := fnv.New64a()
+	fmt.Fprint(h, record[sc.Key])
+
+	return h.Sum64()%samplingScale < sc.threshold
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSampling_Ratio(ratio float64) *float64 {
+	return &ratio
+}
+
+func TestSamplingConfig_sample(t *testing.T) {
+	sc := &SamplingConfig{
+		Ratio: testSampling_Ratio(0.2),
+		Key:   "path",
+		Keep:  []string{"status >= 500"},
+	}
+	require.NoError(t, sc.Init(testRules_Fields))
+
+	kept := 0
+	for i := range 1000 {
+		record := map[string]any{
+			"status": int64(200),
+			"path":   fmt.Sprintf("/item/%d", i),
+		}
+
+		result := sc.sample(record)
+		if result {
+			kept++
+		}
+
+		// Same key gives the same result
+		require.Equal(t, result, sc.sample(record))
+
+		// Errors are always kept
+		record["status"] = int64(500)
+		require.True(t, sc.sample(record))
+	}
+
+	require.InDelta(t, 200, kept, 50)
+}
+
+func TestSamplingConfig_Init(t *testing.T) {
+	sc := &SamplingConfig{Key: "path"}
+	require.ErrorContains(t, sc.Init(testRules_Fields), "ratio is required")
+
+	// Explicit zero drops all records
+	sc = &SamplingConfig{Ratio: testSampling_Ratio(0)}
+	require.NoError(t, sc.Init(testRules_Fields))
+	require.False(t, sc.sample(map[string]any{"status": int64(200)}))
+
+	sc = &SamplingConfig{Ratio: testSampling_Ratio(1.5)}
+	require.ErrorContains(t, sc.Init(testRules_Fields), "ratio should be between 0 and 1")
+
+	sc = &SamplingConfig{Ratio: testSampling_Ratio(0.5), Key: "request_id"}
+	require.ErrorContains(t, sc.Init(testRules_Fields), "unknown key field request_id")
+
+	sc = &SamplingConfig{Ratio: testSampling_Ratio(0.5), Keep: []string{"raw.status == 500"}}
+	require.ErrorContains(t, sc.Init(testRules_Fields), "raw values are not available")
+}
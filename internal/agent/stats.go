@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"maps"
+	"sync"
+)
+
+// agentStats is a set of named counters for the agent pipeline.
+type agentStats struct {
+	mutex    sync.Mutex
+	counters map[string]int64
+}
+
+func (s *agentStats) add(name string, delta int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.counters == nil {
+		s.counters = make(map[string]int64)
+	}
+
+	s.counters[name] += delta
+}
+
+func (s *agentStats) snapshot() map[string]int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.counters == nil {
+		return make(map[string]int64)
+	}
+
+	return maps.Clone(s.counters)
+}
@@ -1,11 +1,18 @@
 package field
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/netip"
 	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/fugo-app/fugo/pkg/bytesize"
+	"github.com/fugo-app/fugo/pkg/duration"
 )
 
 type fieldConverter interface {
@@ -13,6 +20,11 @@ type fieldConverter interface {
 	Convert(map[string]string) (any, error)
 }
 
+// recordConverter is implemented by converters that use previously converted fields of the record.
+type recordConverter interface {
+	ConvertRecord(data map[string]string, record map[string]any) (any, error)
+}
+
 // Field represents a field in the log record.
 type Field struct {
 	// Name of the field in the log record.
@@ -21,14 +33,28 @@ type Field struct {
 	Description string `yaml:"description,omitempty"`
 	// Source field name to extract the value from.
 	Source string `yaml:"source,omitempty"`
-	// Feild type: "string" (default), "int", "float", "time" (default for field with time_format).
+	// Feild type: "string" (default), "int", "float", "time" (default for field with time_format),
+	// "bool" (true/false, yes/no, on/off, 1/0), "json" (validated JSON text), "ip" (normalized IP address),
+	// "duration" (e.g. "123.4ms", "2.1s", value without unit is in seconds),
+	// "bytes" (e.g. "1.5KB", "3MiB", value without unit is in bytes, 1KB is 1024 bytes).
 	Type string `yaml:"type,omitempty"`
+	// Unit to store the duration field: "ms" (default) or "us".
+	Unit string `yaml:"unit,omitempty"`
 	// Index indicates if the field should be indexed.
 	Index bool `yaml:"index,omitempty"`
 	// Template to convert source fields into new record field.
+	// Templates have access to the source fields and to the fields defined above.
+	// Result is converted to the field type, "string" by default.
 	Template string `yaml:"template,omitempty"`
 	// Layout to parse the time string. Only for the "time" field.
 	Timestamp *TimestampFormat `yaml:"timestamp,omitempty"`
+	// Transforms applied to the source value in order before the type conversion.
+	Transform []*Transform `yaml:"transform,omitempty"`
+	// Policy for the time field if the value is missing or could not be parsed:
+	// "now" to use the ingestion time, "drop" to reject the record,
+	// "flag" to use the ingestion time and set the "<name>_fallback" field to true.
+	// By default the record is stored with zero time and the error is reported.
+	OnError string `yaml:"on_error,omitempty"`
 
 	converter fieldConverter
 }
@@ -42,8 +68,11 @@ func (f *Field) Clone() *Field {
 		Name:      f.Name,
 		Source:    f.Source,
 		Type:      f.Type,
+		Unit:      f.Unit,
 		Template:  f.Template,
 		Timestamp: f.Timestamp.Clone(),
+		Transform: cloneTransforms(f.Transform),
+		OnError:   f.OnError,
 	}
 }
 
@@ -56,58 +85,141 @@ func (f *Field) Init() error {
 		return fmt.Errorf("invalid field name '%s': double underscore is not allowed", f.Name)
 	}
 
+	switch f.OnError {
+	case "":
+	case "now", "drop", "flag":
+		if f.Type != "time" && f.Timestamp == nil {
+			return fmt.Errorf("on_error is allowed only for time field '%s'", f.Name)
+		}
+	default:
+		return fmt.Errorf("invalid on_error '%s' for field '%s'", f.OnError, f.Name)
+	}
+
 	source := f.Source
 	if source == "" {
 		source = f.Name
 	}
 
-	if f.Timestamp != nil {
-		f.Type = "time"
+	if len(f.Transform) == 0 && f.Template == "" {
+		converter, err := f.newConverter(source)
+		if err != nil {
+			return err
+		}
+		f.converter = converter
 
-		if err := f.Timestamp.Init(); err != nil {
-			return fmt.Errorf("invalid timestamp format: %w", err)
+		return nil
+	}
+
+	for i, t := range f.Transform {
+		if err := t.Init(); err != nil {
+			return fmt.Errorf("invalid transform #%d for field '%s': %w", i+1, f.Name, err)
 		}
+	}
 
-		f.converter = &timestampConverter{
-			source:    source,
-			timestamp: f.Timestamp,
+	// Transforms are applied to the source value or the rendered template
+	// and the result is converted to the field type.
+	var input fieldConverter = &stringConverter{source}
+	if f.Template != "" {
+		tpl, err := template.New(f.Name).Funcs(templateFuncs).Parse(f.Template)
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", f.Name, err)
 		}
+		input = &templateConverter{tpl}
+	}
 
-		return nil
+	output, err := f.newConverter(transformValue)
+	if err != nil {
+		return err
 	}
 
-	if f.Template != "" {
-		f.Type = "string"
+	f.converter = &transformConverter{
+		input:  input,
+		steps:  f.Transform,
+		output: output,
+	}
 
-		if tpl, err := template.New(f.Name).Parse(f.Template); err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", f.Name, err)
-		} else {
-			f.converter = &templateConverter{tpl}
+	return nil
+}
+
+// newConverter returns the converter for the field type.
+func (f *Field) newConverter(source string) (fieldConverter, error) {
+	if f.Timestamp != nil {
+		f.Type = "time"
+
+		if err := f.Timestamp.Init(); err != nil {
+			return nil, fmt.Errorf("invalid timestamp format: %w", err)
 		}
 
-		return nil
+		return &timestampConverter{
+			source:    source,
+			timestamp: f.Timestamp,
+		}, nil
 	}
 
 	if f.Type == "" {
 		f.Type = "string"
 	}
 
+	if f.Unit != "" && f.Type != "duration" {
+		return nil, fmt.Errorf("unit is allowed only for duration field '%s'", f.Name)
+	}
+
 	switch f.Type {
 	case "string":
-		f.converter = &stringConverter{source}
+		return &stringConverter{source}, nil
 	case "int":
-		f.converter = &intConverter{source}
+		return &intConverter{source}, nil
 	case "float":
-		f.converter = &floatConverter{source}
+		return &floatConverter{source}, nil
+	case "bool":
+		return &boolConverter{source}, nil
+	case "json":
+		return &jsonConverter{source}, nil
+	case "ip":
+		return &ipConverter{source}, nil
+	case "duration":
+		unit, err := durationUnit(f.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unit '%s' for field '%s'", f.Unit, f.Name)
+		}
+		return &durationConverter{source, unit}, nil
+	case "bytes":
+		return &bytesConverter{source}, nil
 	case "time":
 		// if f.Timestamp is not defined then converter is not needed
 		// process as unix timestamp in milliseconds
-		f.converter = &intConverter{source}
+		return &intConverter{source}, nil
 	default:
-		return fmt.Errorf("invalid field type '%s' for field '%s'", f.Type, f.Name)
+		return nil, fmt.Errorf("invalid field type '%s' for field '%s'", f.Type, f.Name)
 	}
+}
 
-	return nil
+// FilterValue converts the query filter value with units to the stored value,
+// e.g. "1.5s" to "1500" for the duration field in milliseconds or "2KB" to "2048" for the bytes field.
+// Values without unit are converted as on ingestion: seconds for the duration field and bytes for the bytes field.
+// Values of other field types are returned as is.
+func (f *Field) FilterValue(val string) (string, error) {
+	var result int64
+
+	switch f.Type {
+	case "duration":
+		unit, err := durationUnit(f.Unit)
+		if err != nil {
+			return "", err
+		}
+		if result, err = parseDuration(val, unit); err != nil {
+			return "", err
+		}
+	case "bytes":
+		var err error
+		if result, err = bytesize.Parse(val); err != nil {
+			return "", err
+		}
+	default:
+		return val, nil
+	}
+
+	return strconv.FormatInt(result, 10), nil
 }
 
 func (f *Field) Default() any {
@@ -119,6 +231,16 @@ func (f *Field) Convert(data map[string]string) (any, error) {
 	return f.converter.Convert(data)
 }
 
+// ConvertRecord converts the field value from the source data
+// and fields of the record converted before this field.
+func (f *Field) ConvertRecord(data map[string]string, record map[string]any) (any, error) {
+	if rc, ok := f.converter.(recordConverter); ok {
+		return rc.ConvertRecord(data, record)
+	}
+
+	return f.converter.Convert(data)
+}
+
 type templateConverter struct {
 	tpl *template.Template
 }
@@ -128,12 +250,26 @@ func (t *templateConverter) Default() any {
 }
 
 func (t *templateConverter) Convert(data map[string]string) (any, error) {
+	return t.ConvertRecord(data, nil)
+}
+
+// ConvertRecord executes the template with source values and converted fields.
+// Converted fields override source values with the same name.
+func (t *templateConverter) ConvertRecord(data map[string]string, record map[string]any) (any, error) {
+	values := make(map[string]any, len(data)+len(record))
+	for key, val := range data {
+		values[key] = val
+	}
+	for key, val := range record {
+		values[key] = val
+	}
+
 	var str strings.Builder
-	if err := t.tpl.Execute(&str, data); err == nil {
-		return str.String(), nil
-	} else {
+	if err := t.tpl.Execute(&str, values); err != nil {
 		return nil, err
 	}
+
+	return str.String(), nil
 }
 
 type timestampConverter struct {
@@ -208,3 +344,155 @@ func (f *floatConverter) Convert(data map[string]string) (any, error) {
 
 	return nil, nil
 }
+
+type boolConverter struct {
+	source string
+}
+
+func (b *boolConverter) Default() any {
+	return false
+}
+
+func (b *boolConverter) Convert(data map[string]string) (any, error) {
+	val, ok := data[b.source]
+	if !ok || strings.TrimSpace(val) == "" {
+		return nil, nil
+	}
+
+	return ParseBool(val)
+}
+
+// ParseBool parses true/false, yes/no, on/off, 1/0 values in any case.
+// Used for the bool fields and the query filters on them.
+func ParseBool(val string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "true", "t", "yes", "y", "on", "1":
+		return true, nil
+	case "false", "f", "no", "n", "off", "0":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid bool value: %q", val)
+}
+
+type jsonConverter struct {
+	source string
+}
+
+func (j *jsonConverter) Default() any {
+	return "null"
+}
+
+func (j *jsonConverter) Convert(data map[string]string) (any, error) {
+	val, ok := data[j.source]
+	if !ok || strings.TrimSpace(val) == "" {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(val)); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+type ipConverter struct {
+	source string
+}
+
+func (i *ipConverter) Default() any {
+	return ""
+}
+
+func (i *ipConverter) Convert(data map[string]string) (any, error) {
+	val, ok := data[i.source]
+	if !ok {
+		return nil, nil
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" || val == "-" {
+		return nil, nil
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(val, "[]"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip address: %q", val)
+	}
+
+	// IPv4-mapped IPv6 addresses are stored as IPv4
+	return addr.Unmap().WithZone("").String(), nil
+}
+
+// durationUnit returns the unit to store the duration field.
+func durationUnit(unit string) (time.Duration, error) {
+	switch unit {
+	case "", "ms":
+		return time.Millisecond, nil
+	case "us":
+		return time.Microsecond, nil
+	}
+
+	return 0, fmt.Errorf("invalid duration unit: %q", unit)
+}
+
+// parseDuration parses the duration with units and returns the rounded number of units.
+func parseDuration(val string, unit time.Duration) (int64, error) {
+	val = strings.TrimSpace(val)
+	if !duration.Match(val) {
+		return 0, fmt.Errorf("invalid duration value: %q", val)
+	}
+
+	d, err := duration.Parse(val)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(d.Round(unit) / unit), nil
+}
+
+type durationConverter struct {
+	source string
+	unit   time.Duration
+}
+
+func (d *durationConverter) Default() any {
+	return int64(0)
+}
+
+func (d *durationConverter) Convert(data map[string]string) (any, error) {
+	val, ok := data[d.source]
+	if !ok {
+		return nil, nil
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" || val == "-" {
+		return nil, nil
+	}
+
+	return parseDuration(val, d.unit)
+}
+
+type bytesConverter struct {
+	source string
+}
+
+func (b *bytesConverter) Default() any {
+	return int64(0)
+}
+
+func (b *bytesConverter) Convert(data map[string]string) (any, error) {
+	val, ok := data[b.source]
+	if !ok {
+		return nil, nil
+	}
+
+	val = strings.TrimSpace(val)
+	if val == "" || val == "-" {
+		return nil, nil
+	}
+
+	return bytesize.Parse(val)
+}
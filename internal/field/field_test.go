@@ -150,6 +150,235 @@ func TestField_Convert(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "process bool field",
+			field: Field{
+				Name: "value",
+				Type: "bool",
+			},
+			data: map[string]string{
+				"value": "Yes",
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "process bool field with zero",
+			field: Field{
+				Name: "value",
+				Type: "bool",
+			},
+			data: map[string]string{
+				"value": "0",
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "process empty bool",
+			field: Field{
+				Name: "value",
+				Type: "bool",
+			},
+			data: map[string]string{
+				"value": "",
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "process invalid bool",
+			field: Field{
+				Name: "value",
+				Type: "bool",
+			},
+			data: map[string]string{
+				"value": "maybe",
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "process json field",
+			field: Field{
+				Name: "value",
+				Type: "json",
+			},
+			data: map[string]string{
+				"value": `{ "user": { "id": 42 } }`,
+			},
+			want:    `{"user":{"id":42}}`,
+			wantErr: false,
+		},
+		{
+			name: "process invalid json",
+			field: Field{
+				Name: "value",
+				Type: "json",
+			},
+			data: map[string]string{
+				"value": `{"user":`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "process ip field",
+			field: Field{
+				Name: "value",
+				Type: "ip",
+			},
+			data: map[string]string{
+				"value": "2001:DB8::0001",
+			},
+			want:    "2001:db8::1",
+			wantErr: false,
+		},
+		{
+			name: "process ipv4-mapped ip field",
+			field: Field{
+				Name: "value",
+				Type: "ip",
+			},
+			data: map[string]string{
+				"value": "::ffff:10.0.0.1",
+			},
+			want:    "10.0.0.1",
+			wantErr: false,
+		},
+		{
+			name: "process empty ip field",
+			field: Field{
+				Name: "value",
+				Type: "ip",
+			},
+			data: map[string]string{
+				"value": "-",
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "process invalid ip",
+			field: Field{
+				Name: "value",
+				Type: "ip",
+			},
+			data: map[string]string{
+				"value": "10.0.0.256",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "process duration field",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+			},
+			data: map[string]string{
+				"value": "123.4ms",
+			},
+			want:    int64(123),
+			wantErr: false,
+		},
+		{
+			name: "process duration field in microseconds",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+				Unit: "us",
+			},
+			data: map[string]string{
+				"value": "123.4ms",
+			},
+			want:    int64(123400),
+			wantErr: false,
+		},
+		{
+			name: "process duration field without unit",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+			},
+			data: map[string]string{
+				"value": "0.125",
+			},
+			want:    int64(125),
+			wantErr: false,
+		},
+		{
+			name: "process compound duration field",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+			},
+			data: map[string]string{
+				"value": "1m2.1s",
+			},
+			want:    int64(62100),
+			wantErr: false,
+		},
+		{
+			name: "process empty duration field",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+			},
+			data: map[string]string{
+				"value": "-",
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name: "process invalid duration",
+			field: Field{
+				Name: "value",
+				Type: "duration",
+			},
+			data: map[string]string{
+				"value": "10 parsecs",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "process bytes field",
+			field: Field{
+				Name: "value",
+				Type: "bytes",
+			},
+			data: map[string]string{
+				"value": "1.5KB",
+			},
+			want:    int64(1536),
+			wantErr: false,
+		},
+		{
+			name: "process bytes field without unit",
+			field: Field{
+				Name: "value",
+				Type: "bytes",
+			},
+			data: map[string]string{
+				"value": "4096",
+			},
+			want:    int64(4096),
+			wantErr: false,
+		},
+		{
+			name: "process invalid bytes",
+			field: Field{
+				Name: "value",
+				Type: "bytes",
+			},
+			data: map[string]string{
+				"value": "3 apples",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,3 +395,57 @@ func TestField_Convert(t *testing.T) {
 		})
 	}
 }
+
+func TestField_Unit(t *testing.T) {
+	field := &Field{Name: "value", Type: "duration", Unit: "s"}
+	require.ErrorContains(t, field.Init(), "invalid unit 's' for field 'value'")
+
+	field = &Field{Name: "value", Type: "int", Unit: "ms"}
+	require.ErrorContains(t, field.Init(), "unit is allowed only for duration field 'value'")
+}
+
+func TestField_FilterValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"duration", Field{Name: "value", Type: "duration"}, "1.5s", "1500", false},
+		{"duration in microseconds", Field{Name: "value", Type: "duration", Unit: "us"}, "2ms", "2000", false},
+		{"duration without unit", Field{Name: "value", Type: "duration"}, "1", "1000", false},
+		{"float duration without unit", Field{Name: "value", Type: "duration"}, "1.0", "1000", false},
+		{"invalid duration", Field{Name: "value", Type: "duration"}, "soon", "", true},
+		{"bytes", Field{Name: "value", Type: "bytes"}, "2MB", "2097152", false},
+		{"bytes without unit", Field{Name: "value", Type: "bytes"}, "250", "250", false},
+		{"invalid bytes", Field{Name: "value", Type: "bytes"}, "big", "", true},
+		{"other type", Field{Name: "value", Type: "string"}, "1.5s", "1.5s", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.field.Init())
+
+			got, err := tt.field.FilterValue(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestField_OnError(t *testing.T) {
+	field := &Field{Name: "time", Timestamp: &TimestampFormat{}, OnError: "now"}
+	require.NoError(t, field.Init())
+	require.Equal(t, "now", field.Clone().OnError)
+
+	field = &Field{Name: "time", Type: "time", OnError: "ignore"}
+	require.ErrorContains(t, field.Init(), "invalid on_error 'ignore' for field 'time'")
+
+	field = &Field{Name: "status", Type: "int", OnError: "drop"}
+	require.ErrorContains(t, field.Init(), "on_error is allowed only for time field 'status'")
+}
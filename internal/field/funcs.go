@@ -0,0 +1,394 @@
+package field
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// templateFuncs is a library of functions available in field templates.
+// Functions take the value as the last argument to be used in pipelines:
+//
+//	{{ .level | upper }}
+//	{{ .path | trimPrefix "/api" | default "/" }}
+//	{{ div .bytes 1024 | round 2 }}
+var templateFuncs = template.FuncMap{
+	// Strings
+	"upper":      func(v any) string { return strings.ToUpper(toString(v)) },
+	"lower":      func(v any) string { return strings.ToLower(toString(v)) },
+	"trim":       func(v any) string { return strings.TrimSpace(toString(v)) },
+	"trimPrefix": func(prefix string, v any) string { return strings.TrimPrefix(toString(v), prefix) },
+	"trimSuffix": func(suffix string, v any) string { return strings.TrimSuffix(toString(v), suffix) },
+	"replace":    func(old, new string, v any) string { return strings.ReplaceAll(toString(v), old, new) },
+	"contains":   func(substr string, v any) bool { return strings.Contains(toString(v), substr) },
+	"hasPrefix":  func(prefix string, v any) bool { return strings.HasPrefix(toString(v), prefix) },
+	"hasSuffix":  func(suffix string, v any) bool { return strings.HasSuffix(toString(v), suffix) },
+	"split":      func(sep string, v any) []string { return strings.Split(toString(v), sep) },
+	"join":       func(sep string, v []string) string { return strings.Join(v, sep) },
+	"substr":     substr,
+	"default":    defaultValue,
+	"coalesce":   coalesce,
+
+	// Regular expressions
+	"regexMatch":   regexMatch,
+	"regexFind":    regexFind,
+	"regexReplace": regexReplace,
+
+	// Math
+	"add":   add,
+	"sub":   subtract,
+	"mul":   multiply,
+	"div":   divide,
+	"mod":   modulo,
+	"round": round,
+	"int":   toInt,
+	"float": toFloat,
+
+	// Time, values are unix timestamps in milliseconds
+	"now":        func() int64 { return time.Now().UnixMilli() },
+	"formatTime": formatTime,
+	"parseTime":  parseTime,
+
+	// Hashing, returns hex string
+	"md5":    func(v any) string { return hashString(md5.New(), v) },
+	"sha1":   func(v any) string { return hashString(sha1.New(), v) },
+	"sha256": func(v any) string { return hashString(sha256.New(), v) },
+	"fnv":    func(v any) string { return hashString(fnv.New64a(), v) },
+
+	// JSON
+	"toJson":  toJson,
+	"jsonGet": jsonGet,
+}
+
+// toString converts the value to string, nil is converted to empty string.
+func toString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func isEmpty(v any) bool {
+	switch s := v.(type) {
+	case nil:
+		return true
+	case string:
+		return s == ""
+	case int64:
+		return s == 0
+	case int:
+		return s == 0
+	case float64:
+		return s == 0
+	case bool:
+		return !s
+	}
+
+	return false
+}
+
+// defaultValue returns the value or the default if the value is empty.
+func defaultValue(def any, v any) any {
+	if isEmpty(v) {
+		return def
+	}
+	return v
+}
+
+// coalesce returns the first non-empty value.
+func coalesce(values ...any) any {
+	for _, v := range values {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// substr returns the part of the string by rune indexes, negative end counts from the end.
+func substr(start, end int, v any) string {
+	runes := []rune(toString(v))
+
+	if end < 0 {
+		end += len(runes)
+	}
+	start = max(0, min(start, len(runes)))
+	end = max(start, min(end, len(runes)))
+
+	return string(runes[start:end])
+}
+
+var regexCache sync.Map
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+
+	return re, nil
+}
+
+func regexMatch(pattern string, v any) (bool, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(toString(v)), nil
+}
+
+func regexFind(pattern string, v any) (string, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(toString(v)), nil
+}
+
+func regexReplace(pattern string, repl string, v any) (string, error) {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(toString(v), repl), nil
+}
+
+// toNumber converts the value to int64 if possible or float64 otherwise.
+func toNumber(v any) (int64, float64, bool, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), 0, true, nil
+	case int64:
+		return n, 0, true, nil
+	case float64:
+		return 0, n, false, nil
+	}
+
+	s := strings.TrimSpace(toString(v))
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, 0, true, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid number: %q", s)
+	}
+
+	return 0, f, false, nil
+}
+
+func toInt(v any) (int64, error) {
+	i, f, isInt, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	if isInt {
+		return i, nil
+	}
+	return int64(f), nil
+}
+
+func toFloat(v any) (float64, error) {
+	i, f, isInt, err := toNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	if isInt {
+		return float64(i), nil
+	}
+	return f, nil
+}
+
+// arith applies the integer operation if both values are integers, float operation otherwise.
+func arith(a, b any, intOp func(int64, int64) int64, floatOp func(float64, float64) float64) (any, error) {
+	ai, af, aInt, err := toNumber(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bi, bf, bInt, err := toNumber(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if aInt && bInt {
+		return intOp(ai, bi), nil
+	}
+
+	if aInt {
+		af = float64(ai)
+	}
+	if bInt {
+		bf = float64(bi)
+	}
+
+	return floatOp(af, bf), nil
+}
+
+func add(a, b any) (any, error) {
+	return arith(a, b,
+		func(x, y int64) int64 { return x + y },
+		func(x, y float64) float64 { return x + y },
+	)
+}
+
+func subtract(a, b any) (any, error) {
+	return arith(a, b,
+		func(x, y int64) int64 { return x - y },
+		func(x, y float64) float64 { return x - y },
+	)
+}
+
+func multiply(a, b any) (any, error) {
+	return arith(a, b,
+		func(x, y int64) int64 { return x * y },
+		func(x, y float64) float64 { return x * y },
+	)
+}
+
+func divide(a, b any) (float64, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if y == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+
+	return x / y, nil
+}
+
+func modulo(a, b any) (int64, error) {
+	x, err := toInt(a)
+	if err != nil {
+		return 0, err
+	}
+
+	y, err := toInt(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if y == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+
+	return x % y, nil
+}
+
+// round rounds the value to the number of decimal places.
+func round(places int, v any) (float64, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+
+	p := math.Pow(10, float64(places))
+
+	return math.Round(f*p) / p, nil
+}
+
+// formatTime formats the unix timestamp in milliseconds with Go layout in UTC.
+func formatTime(layout string, v any) (string, error) {
+	ms, err := toInt(v)
+	if err != nil {
+		return "", err
+	}
+
+	return time.UnixMilli(ms).UTC().Format(layout), nil
+}
+
+// parseTime parses the time with Go layout and returns unix timestamp in milliseconds.
+func parseTime(layout string, v any) (int64, error) {
+	t, err := time.Parse(layout, toString(v))
+	if err != nil {
+		return 0, err
+	}
+
+	return t.UnixMilli(), nil
+}
+
+func hashString(h interface {
+	Write([]byte) (int, error)
+	Sum([]byte) []byte
+}, v any) string {
+	h.Write([]byte(toString(v)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func toJson(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// jsonGet returns the value from JSON string by the dot-separated path, e.g. "user.id" or "items.0".
+// Returns nil if the path does not exist.
+func jsonGet(path string, v any) (any, error) {
+	var data any
+
+	decoder := json.NewDecoder(strings.NewReader(toString(v)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch node := data.(type) {
+			case map[string]any:
+				data = node[key]
+			case []any:
+				idx, err := strconv.Atoi(key)
+				if err != nil || idx < 0 || idx >= len(node) {
+					return nil, nil
+				}
+				data = node[idx]
+			default:
+				return nil, nil
+			}
+		}
+	}
+
+	switch node := data.(type) {
+	case json.Number:
+		if i, err := node.Int64(); err == nil {
+			return i, nil
+		}
+		return node.Float64()
+	case map[string]any, []any:
+		return toJson(node)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,119 @@
+package field
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestField_TemplateFuncs(t *testing.T) {
+	data := map[string]string{
+		"level":   " Warning ",
+		"path":    "/api/v1/users",
+		"bytes":   "1536",
+		"elapsed": "0.125",
+		"payload": `{"user":{"id":42,"tags":["a","b"]},"ratio":0.5}`,
+		"empty":   "",
+	}
+
+	tests := []struct {
+		template string
+		typ      string
+		want     any
+	}{
+		{`{{ .level | trim | lower }}`, "", "warning"},
+		{`{{ .level | upper | trim }}`, "", "WARNING"},
+		{`{{ .path | trimPrefix "/api" }}`, "", "/v1/users"},
+		{`{{ .path | replace "/" "." | trimPrefix "." }}`, "", "api.v1.users"},
+		{`{{ if .path | hasPrefix "/api" }}api{{ else }}web{{ end }}`, "", "api"},
+		{`{{ index (split "/" .path) 2 }}`, "", "v1"},
+		{`{{ split "/" .path | join "-" }}`, "", "-api-v1-users"},
+		{`{{ .path | substr 0 4 }}`, "", "/api"},
+		{`{{ .path | substr 8 -1 }}`, "", "user"},
+		{`{{ .empty | default "none" }}`, "", "none"},
+		{`{{ coalesce .missing .empty .level }}`, "", " Warning "},
+		{`{{ printf "%s:%s" "x" .bytes }}`, "", "x:1536"},
+		{`{{ .path | regexReplace "/v\\d+" "" }}`, "", "/api/users"},
+		{`{{ .path | regexFind "v\\d+" }}`, "", "v1"},
+		{`{{ regexMatch "^/api" .path }}`, "", "true"},
+		{`{{ div .bytes 1024 }}`, "float", 1.5},
+		{`{{ mul .elapsed 1000 }}`, "int", int64(125)},
+		{`{{ add .bytes 1 }}`, "int", int64(1537)},
+		{`{{ sub .bytes 0.5 }}`, "float", 1535.5},
+		{`{{ mod .bytes 1000 }}`, "int", int64(536)},
+		{`{{ div 2 3 | round 2 }}`, "float", 0.67},
+		{`{{ int .elapsed }}`, "int", int64(0)},
+		{`{{ formatTime "2006-01-02" 1672574400000 }}`, "", "2023-01-01"},
+		{`{{ parseTime "2006-01-02" "2023-01-01" }}`, "time", int64(1672531200000)},
+		{`{{ .path | md5 }}`, "", "9710c920e97f2e0f81f0a4c824c5db9e"},
+		{`{{ .path | sha1 | substr 0 8 }}`, "", "723d978c"},
+		{`{{ .path | sha256 | substr 0 8 }}`, "", "5f59cc57"},
+		{`{{ .path | fnv }}`, "", "b0bada58577b0ef9"},
+		{`{{ jsonGet "user.id" .payload }}`, "int", int64(42)},
+		{`{{ jsonGet "user.tags.1" .payload }}`, "", "b"},
+		{`{{ jsonGet "user.tags" .payload }}`, "", `["a","b"]`},
+		{`{{ jsonGet "ratio" .payload }}`, "float", 0.5},
+		{`{{ jsonGet "user.name" .payload | default "anonymous" }}`, "", "anonymous"},
+		{`{{ toJson .path }}`, "", `"/api/v1/users"`},
+		{`{{ .empty }}`, "int", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			field := &Field{
+				Name:     "test",
+				Type:     tt.typ,
+				Template: tt.template,
+			}
+			require.NoError(t, field.Init())
+
+			got, err := field.Convert(data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestField_TemplateError(t *testing.T) {
+	field := &Field{
+		Name:     "test",
+		Type:     "float",
+		Template: `{{ div .bytes 0 }}`,
+	}
+	require.NoError(t, field.Init())
+
+	_, err := field.Convert(map[string]string{"bytes": "1"})
+	require.ErrorContains(t, err, "division by zero")
+
+	field = &Field{
+		Name:     "test",
+		Template: `{{ unknown .bytes }}`,
+	}
+	require.ErrorContains(t, field.Init(), "function \"unknown\" not defined")
+}
+
+func TestField_ConvertRecord(t *testing.T) {
+	fields := []*Field{
+		{Name: "status", Type: "int"},
+		{Name: "bytes", Type: "int"},
+		{Name: "status_class", Type: "int", Template: `{{ div .status 100 | int }}`},
+		{Name: "summary", Template: `{{ .status_class }}xx {{ mul .bytes 2 }}`},
+	}
+
+	data := map[string]string{
+		"status": "503",
+		"bytes":  "10",
+	}
+
+	record := make(map[string]any)
+	for _, f := range fields {
+		require.NoError(t, f.Init())
+
+		val, err := f.ConvertRecord(data, record)
+		require.NoError(t, err)
+		record[f.Name] = val
+	}
+
+	require.Equal(t, int64(5), record["status_class"])
+	require.Equal(t, "5xx 20", record["summary"])
+}
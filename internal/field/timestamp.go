@@ -18,9 +18,17 @@ type timeParser interface {
 type TimestampFormat struct {
 	// Format of the timestamp.
 	// Default: "rfc3339"
-	// Supported: "rfc3339", "common", "unix", or custom Go layout (e.g. "2006-01-02 15:04:05")
+	// Supported: "rfc3339", "rfc3339nano", "iso8601", "common", "stamp", "syslog", "unix",
+	// or custom Go layout (e.g. "2006-01-02 15:04:05")
 	Format string `yaml:"format,omitempty"`
 
+	// Fallback formats tried in order if the timestamp does not match the format.
+	Fallback []string `yaml:"fallback,omitempty"`
+
+	// Timezone for timestamps without zone offset: IANA name (e.g. "Europe/Berlin") or "local".
+	// Default: "UTC"
+	Timezone string `yaml:"timezone,omitempty"`
+
 	// Go time layout used in time.Parse
 	parser timeParser
 }
@@ -30,85 +38,230 @@ func (t *TimestampFormat) Clone() *TimestampFormat {
 		return nil
 	}
 
+	var fallback []string
+	if t.Fallback != nil {
+		fallback = append([]string(nil), t.Fallback...)
+	}
+
 	return &TimestampFormat{
-		Format: t.Format,
+		Format:   t.Format,
+		Fallback: fallback,
+		Timezone: t.Timezone,
 	}
 }
 
 // Init initializes the TimestampFormat by converting the Format field
 // to the corresponding Go time layout format stored in layout.
 func (t *TimestampFormat) Init() error {
-	format := strings.ToLower(t.Format)
+	loc, err := loadLocation(t.Timezone)
+	if err != nil {
+		return err
+	}
+
+	format := t.Format
 	if format == "" {
 		format = "rfc3339"
 	}
 
+	if len(t.Fallback) == 0 {
+		t.parser = newTimeParser(format, loc)
+		return nil
+	}
+
+	parsers := []timeParser{newTimeParser(format, loc)}
+	for i, fallback := range t.Fallback {
+		if fallback == "" {
+			return fmt.Errorf("fallback format #%d is empty", i+1)
+		}
+		parsers = append(parsers, newTimeParser(fallback, loc))
+	}
+	t.parser = &fallbackTimeParser{parsers}
+
+	return nil
+}
+
+// Convert processes a log record and converts the timestamp field specified by Source
+// to unix timestamp format with millisecond precision.
+func (t *TimestampFormat) Convert(source string) (int64, error) {
+	return t.parser.Parse(source)
+}
+
+func loadLocation(timezone string) (*time.Location, error) {
+	switch strings.ToLower(timezone) {
+	case "", "utc":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", timezone, err)
+	}
+
+	return loc, nil
+}
+
+// newTimeParser returns the parser for the named format or Go time layout.
+func newTimeParser(format string, loc *time.Location) timeParser {
 	// Convert named formats to Go time layout format
-	switch format {
+	switch strings.ToLower(format) {
 	case "rfc3339":
-		t.parser = &stdTimeParser{
-			layout: time.RFC3339,
-		}
+		return newStdTimeParser(loc, time.RFC3339)
 	case "rfc3339nano":
-		t.parser = &stdTimeParser{
-			layout: time.RFC3339Nano,
+		return newStdTimeParser(loc, time.RFC3339Nano)
+	case "iso8601":
+		// Fractions with dot or comma are accepted after seconds for any layout
+		return &isoTimeParser{
+			stdTimeParser: newStdTimeParser(loc,
+				"2006-01-02T15:04:05Z07:00",
+				"2006-01-02T15:04:05Z0700",
+				"2006-01-02T15:04:05Z07",
+				"2006-01-02T15:04:05",
+				"2006-01-02T15:04Z07:00",
+				"2006-01-02T15:04",
+				"20060102T150405Z0700",
+				"20060102T150405",
+				"2006-01-02",
+			),
 		}
 	case "common":
 		// Common log format used by web servers
-		t.parser = &stdTimeParser{
-			layout: "02/Jan/2006:15:04:05 -0700",
-		}
+		return newStdTimeParser(loc, "02/Jan/2006:15:04:05 -0700")
 	case "stamp":
-		t.parser = &stdTimeParser{
-			layout: time.Stamp,
-		}
+		return newStdTimeParser(loc, time.Stamp)
+	case "syslog":
+		// BSD syslog (RFC 3164) without year, RFC 5424 is handled by rfc3339
+		return newStdTimeParser(loc, time.Stamp, "Jan _2 2006 15:04:05")
 	case "unix":
 		// Unix timestamp doesn't need a layout as it will be parsed differently
-		t.parser = &unixTimeParser{}
+		return &unixTimeParser{}
 	default:
 		// Assume Format is already in Go's time layout syntax
-		t.parser = &stdTimeParser{
-			layout: t.Format,
-		}
+		return newStdTimeParser(loc, format)
 	}
+}
 
-	return nil
+type fallbackTimeParser struct {
+	parsers []timeParser
 }
 
-// Convert processes a log record and converts the timestamp field specified by Source
-// to unix timestamp format with millisecond precision.
-func (t *TimestampFormat) Convert(source string) (int64, error) {
-	return t.parser.Parse(source)
+// Parse returns the result of the first matched parser or the error of the main format.
+func (p *fallbackTimeParser) Parse(source string) (int64, error) {
+	var first error
+
+	for _, parser := range p.parsers {
+		result, err := parser.Parse(source)
+		if err == nil {
+			return result, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	return 0, first
 }
 
 type stdTimeParser struct {
-	layout string
+	layouts []string
+	noYear  []bool
+	loc     *time.Location
 }
 
-func (p *stdTimeParser) Parse(source string) (int64, error) {
-	parsedTime, err := time.Parse(p.layout, source)
+func newStdTimeParser(loc *time.Location, layouts ...string) *stdTimeParser {
+	p := &stdTimeParser{
+		layouts: layouts,
+		noYear:  make([]bool, len(layouts)),
+		loc:     loc,
+	}
+
+	for i, layout := range layouts {
+		p.noYear[i] = !layoutHasYear(layout)
+	}
+
+	return p
+}
+
+// layoutHasYear checks if the layout contains the year.
+// The reference time is formatted and parsed back, the year is zero if the layout has no year.
+func layoutHasYear(layout string) bool {
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	t, err := time.Parse(layout, ref.Format(layout))
 	if err != nil {
-		return 0, fmt.Errorf("invalid timestamp '%s' (%s): %w", source, p.layout, err)
+		return true
+	}
+
+	return t.Year() != 0
+}
+
+func (p *stdTimeParser) Parse(source string) (int64, error) {
+	var first error
+
+	for i, layout := range p.layouts {
+		parsedTime, err := time.ParseInLocation(layout, source, p.loc)
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf("invalid timestamp '%s' (%s): %w", source, layout, err)
+			}
+			continue
+		}
+
+		if p.noYear[i] {
+			parsedTime = withNearestYear(parsedTime, stdTimeNow())
+		}
+
+		return parsedTime.UnixMilli(), nil
 	}
 
-	if parsedTime.Year() == 0 {
-		now := stdTimeNow()
+	return 0, first
+}
+
+// withNearestYear sets the year for timestamps without year, e.g. in syslog.
+// Selects the closest time to now from previous, current, and next years
+// to handle logs written around the new year.
+func withNearestYear(t time.Time, now time.Time) time.Time {
+	now = now.In(t.Location())
+
+	var result time.Time
+	var best time.Duration
+
+	for year := now.Year() - 1; year <= now.Year()+1; year++ {
+		candidate := time.Date(year, t.Month(), t.Day(),
+			t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if candidate.Day() != t.Day() {
+			// Feb 29 in non-leap year
+			continue
+		}
 
-		// Handle new year case
-		if parsedTime.Month() == 12 && now.Month() == 1 {
-			year := now.Year() - 1
-			parsedTime = parsedTime.AddDate(year, 0, 0)
-		} else {
-			year := now.Year()
-			parsedTime = parsedTime.AddDate(year, 0, 0)
+		diff := candidate.Sub(now).Abs()
+		if result.IsZero() || diff < best {
+			result = candidate
+			best = diff
 		}
 	}
 
-	return parsedTime.UnixMilli(), nil
+	return result
+}
+
+type isoTimeParser struct {
+	*stdTimeParser
+}
+
+func (p *isoTimeParser) Parse(source string) (int64, error) {
+	// Date and time could be separated with space
+	if len(source) > 10 && source[10] == ' ' {
+		source = source[:10] + "T" + source[11:]
+	}
+
+	return p.stdTimeParser.Parse(source)
 }
 
 type unixTimeParser struct{}
 
+// Parse parses the unix timestamp in seconds with optional fraction.
+// Milliseconds, microseconds, and nanoseconds are detected by the number of digits.
 func (unixTimeParser) Parse(source string) (int64, error) {
 	sec, frac, ok := strings.Cut(source, ".")
 	seconds, err := strconv.ParseInt(sec, 10, 64)
@@ -116,6 +269,15 @@ func (unixTimeParser) Parse(source string) (int64, error) {
 		return 0, fmt.Errorf("invalid timestamp '%s' (seconds): %w", source, err)
 	}
 
+	switch digits := len(strings.TrimPrefix(sec, "-")); {
+	case digits > 17:
+		return seconds / 1e6, nil
+	case digits > 14:
+		return seconds / 1e3, nil
+	case digits > 11:
+		return seconds, nil
+	}
+
 	milliseconds := seconds * 1000
 	if !ok {
 		return milliseconds, nil
@@ -129,6 +291,9 @@ func (unixTimeParser) Parse(source string) (int64, error) {
 	multiply := int64(100)
 	for i := 0; i < limit; i++ {
 		ch := frac[i] - '0'
+		if ch > 9 {
+			return 0, fmt.Errorf("invalid timestamp '%s' (fraction)", source)
+		}
 		milliseconds = milliseconds + (int64(ch) * multiply)
 		multiply = multiply / 10
 	}
@@ -78,6 +78,109 @@ func TestTimestampFormat_Convert(t *testing.T) {
 			want:    1672574400000,
 			wantErr: false,
 		},
+		{
+			name: "unix timestamp in milliseconds",
+			timestamp: &TimestampFormat{
+				Format: "unix",
+			},
+			input:   "1672574400123",
+			want:    1672574400123,
+			wantErr: false,
+		},
+		{
+			name: "unix timestamp in microseconds",
+			timestamp: &TimestampFormat{
+				Format: "unix",
+			},
+			input:   "1672574400123456",
+			want:    1672574400123,
+			wantErr: false,
+		},
+		{
+			name: "unix timestamp in nanoseconds",
+			timestamp: &TimestampFormat{
+				Format: "unix",
+			},
+			input:   "1672574400123456789",
+			want:    1672574400123,
+			wantErr: false,
+		},
+		{
+			name: "iso8601 with comma fraction",
+			timestamp: &TimestampFormat{
+				Format: "iso8601",
+			},
+			input:   "2023-01-01T12:00:00,123+0000",
+			want:    1672574400123,
+			wantErr: false,
+		},
+		{
+			name: "iso8601 with space separator",
+			timestamp: &TimestampFormat{
+				Format: "iso8601",
+			},
+			input:   "2023-01-01 14:00:00.5+02:00",
+			want:    1672574400500,
+			wantErr: false,
+		},
+		{
+			name: "iso8601 without zone",
+			timestamp: &TimestampFormat{
+				Format: "iso8601",
+			},
+			input:   "2023-01-01T12:00:00",
+			want:    1672574400000,
+			wantErr: false,
+		},
+		{
+			name: "iso8601 basic format",
+			timestamp: &TimestampFormat{
+				Format: "iso8601",
+			},
+			input:   "20230101T120000Z",
+			want:    1672574400000,
+			wantErr: false,
+		},
+		{
+			name: "custom format with timezone option",
+			timestamp: &TimestampFormat{
+				Format:   "2006-01-02 15:04:05",
+				Timezone: "Asia/Tokyo",
+			},
+			input:   "2023-01-01 21:00:00",
+			want:    1672574400000,
+			wantErr: false,
+		},
+		{
+			name: "timezone option does not override offset",
+			timestamp: &TimestampFormat{
+				Format:   "rfc3339",
+				Timezone: "Asia/Tokyo",
+			},
+			input:   "2023-01-01T12:00:00Z",
+			want:    1672574400000,
+			wantErr: false,
+		},
+		{
+			name: "fallback format",
+			timestamp: &TimestampFormat{
+				Format:   "rfc3339",
+				Fallback: []string{"common", "2006-01-02 15:04:05"},
+			},
+			input:   "2023-01-01 12:00:00",
+			want:    1672574400000,
+			wantErr: false,
+		},
+		{
+			name: "no fallback format matched",
+			timestamp: &TimestampFormat{
+				Format:   "rfc3339",
+				Fallback: []string{"common"},
+			},
+			input:   "01.01.2023",
+			want:    0,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -194,6 +297,61 @@ func TestTimestampFormat_Convert_WithoutYear(t *testing.T) {
 			now:   time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC),
 			want:  time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC).UnixMilli(),
 		},
+		{
+			name: "New Year with clock skew",
+			timestamp: &TimestampFormat{
+				Format: "syslog",
+			},
+			input: "Jan  1 00:00:05",
+			now:   time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC),
+			want:  time.Date(2023, 1, 1, 0, 0, 5, 0, time.UTC).UnixMilli(),
+		},
+		{
+			name: "leap day",
+			timestamp: &TimestampFormat{
+				Format: "syslog",
+			},
+			input: "Feb 29 12:00:00",
+			now:   time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC).UnixMilli(),
+		},
+		{
+			name: "syslog with timezone",
+			timestamp: &TimestampFormat{
+				Format:   "syslog",
+				Timezone: "America/New_York",
+			},
+			input: "Jun 15 08:00:00",
+			now:   time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC).UnixMilli(),
+		},
+		{
+			name: "custom layout with two-digit year",
+			timestamp: &TimestampFormat{
+				Format: "02/01/06 15:04:05",
+			},
+			input: "20/10/21 12:00:00",
+			now:   time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2021, 10, 20, 12, 0, 0, 0, time.UTC).UnixMilli(),
+		},
+		{
+			name: "custom layout with fractional seconds",
+			timestamp: &TimestampFormat{
+				Format: "01/02 15:04:05.000000",
+			},
+			input: "10/20 12:00:00.000006",
+			now:   time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2023, 10, 20, 12, 0, 0, 6000, time.UTC).UnixMilli(),
+		},
+		{
+			name: "syslog with year",
+			timestamp: &TimestampFormat{
+				Format: "syslog",
+			},
+			input: "Oct 20 2021 12:00:00",
+			now:   time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC),
+			want:  time.Date(2021, 10, 20, 12, 0, 0, 0, time.UTC).UnixMilli(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -215,3 +373,37 @@ func TestTimestampFormat_Convert_WithoutYear(t *testing.T) {
 		})
 	}
 }
+
+func Test_layoutHasYear(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   bool
+	}{
+		{"2006-01-02 15:04:05", true},
+		{"02/01/06 15:04", true},
+		{"Jan _2 2006 15:04:05", true},
+		{"2006", true},
+		{time.RFC1123Z, true},
+		{time.Stamp, false},
+		{"Jan 02 15:04:05", false},
+		{"01/02 15:04:05.000000", false},
+		{"15:04:05", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			require.Equal(t, tt.want, layoutHasYear(tt.layout))
+		})
+	}
+}
+
+func TestTimestampFormat_Init(t *testing.T) {
+	ts := &TimestampFormat{Timezone: "Mars/Olympus"}
+	require.ErrorContains(t, ts.Init(), "invalid timezone 'Mars/Olympus'")
+
+	ts = &TimestampFormat{Fallback: []string{"unix", ""}}
+	require.ErrorContains(t, ts.Init(), "fallback format #2 is empty")
+
+	ts = &TimestampFormat{Timezone: "local"}
+	require.NoError(t, ts.Init())
+}
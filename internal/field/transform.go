@@ -0,0 +1,231 @@
+package field
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Transform is a single step to modify the field value.
+// Each step should define exactly one action:
+//
+//	transform:
+//	  - regex: 'user_id=(\d+)'
+//	  - split: "/"
+//	    index: 2
+//	  - replace: '\s+'
+//	    with: " "
+//	  - lower: true
+//	  - trim: true
+//	  - url_decode: true
+//	  - query: user_id
+//
+// If the step has no result (e.g. regex does not match), the field gets the default value.
+type Transform struct {
+	// Regular expression to extract the value.
+	// Returns the first capture group or the whole match if there are no groups.
+	Regex string `yaml:"regex,omitempty"`
+
+	// Separator to split the value.
+	Split string `yaml:"split,omitempty"`
+	// Index of the split part, negative index counts from the end.
+	Index int `yaml:"index,omitempty"`
+
+	// Regular expression to replace.
+	Replace string `yaml:"replace,omitempty"`
+	// Replacement for the replace action, supports "$1" for capture groups.
+	With string `yaml:"with,omitempty"`
+
+	// Convert the value to lower case.
+	Lower bool `yaml:"lower,omitempty"`
+	// Convert the value to upper case.
+	Upper bool `yaml:"upper,omitempty"`
+	// Remove leading and trailing white space.
+	Trim bool `yaml:"trim,omitempty"`
+
+	// Decode the URL-encoded value, e.g. "%2F" or "+".
+	URLDecode bool `yaml:"url_decode,omitempty"`
+
+	// Name of the query parameter to extract from the URL or query string.
+	Query string `yaml:"query,omitempty"`
+
+	fn func(string) (string, bool)
+}
+
+// transformValue is the data key used to pass the transformed value to the type converter.
+const transformValue = ""
+
+func cloneTransforms(list []*Transform) []*Transform {
+	if list == nil {
+		return nil
+	}
+
+	result := make([]*Transform, len(list))
+	for i, t := range list {
+		c := *t
+		c.fn = nil
+		result[i] = &c
+	}
+
+	return result
+}
+
+func (t *Transform) Init() error {
+	var actions []string
+
+	if t.Regex != "" {
+		actions = append(actions, "regex")
+
+		re, err := regexp.Compile(t.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		t.fn = func(val string) (string, bool) {
+			match := re.FindStringSubmatch(val)
+			if match == nil {
+				return "", false
+			}
+			if len(match) > 1 {
+				return match[1], true
+			}
+			return match[0], true
+		}
+	}
+
+	if t.Split != "" {
+		actions = append(actions, "split")
+
+		t.fn = func(val string) (string, bool) {
+			parts := strings.Split(val, t.Split)
+			idx := t.Index
+			if idx < 0 {
+				idx += len(parts)
+			}
+			if idx < 0 || idx >= len(parts) {
+				return "", false
+			}
+			return parts[idx], true
+		}
+	} else if t.Index != 0 {
+		return fmt.Errorf("index requires split")
+	}
+
+	if t.Replace != "" {
+		actions = append(actions, "replace")
+
+		re, err := regexp.Compile(t.Replace)
+		if err != nil {
+			return fmt.Errorf("invalid replace: %w", err)
+		}
+		t.fn = func(val string) (string, bool) {
+			return re.ReplaceAllString(val, t.With), true
+		}
+	} else if t.With != "" {
+		return fmt.Errorf("with requires replace")
+	}
+
+	if t.Lower {
+		actions = append(actions, "lower")
+		t.fn = func(val string) (string, bool) {
+			return strings.ToLower(val), true
+		}
+	}
+
+	if t.Upper {
+		actions = append(actions, "upper")
+		t.fn = func(val string) (string, bool) {
+			return strings.ToUpper(val), true
+		}
+	}
+
+	if t.Trim {
+		actions = append(actions, "trim")
+		t.fn = func(val string) (string, bool) {
+			return strings.TrimSpace(val), true
+		}
+	}
+
+	if t.URLDecode {
+		actions = append(actions, "url_decode")
+		t.fn = func(val string) (string, bool) {
+			if decoded, err := url.QueryUnescape(val); err == nil {
+				return decoded, true
+			}
+			// Keep the value with invalid escape sequences as is
+			return val, true
+		}
+	}
+
+	if t.Query != "" {
+		actions = append(actions, "query")
+		t.fn = func(val string) (string, bool) {
+			if _, query, ok := strings.Cut(val, "?"); ok {
+				val = query
+			}
+			// Fragment is not a part of the query
+			val, _, _ = strings.Cut(val, "#")
+
+			// Invalid pairs are skipped, valid ones are still returned
+			values, _ := url.ParseQuery(val)
+			if !values.Has(t.Query) {
+				return "", false
+			}
+			return values.Get(t.Query), true
+		}
+	}
+
+	switch len(actions) {
+	case 0:
+		return fmt.Errorf("action is required")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("only one action is allowed, got %s", strings.Join(actions, ", "))
+	}
+}
+
+// transformConverter applies transforms to the source value or the rendered template
+// and converts the result with the output converter.
+type transformConverter struct {
+	input  fieldConverter
+	steps  []*Transform
+	output fieldConverter
+}
+
+func (t *transformConverter) Default() any {
+	return t.output.Default()
+}
+
+func (t *transformConverter) Convert(data map[string]string) (any, error) {
+	return t.ConvertRecord(data, nil)
+}
+
+func (t *transformConverter) ConvertRecord(data map[string]string, record map[string]any) (any, error) {
+	var val any
+	var err error
+
+	if rc, ok := t.input.(recordConverter); ok {
+		val, err = rc.ConvertRecord(data, record)
+	} else {
+		val, err = t.input.Convert(data)
+	}
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	str := val.(string)
+	for _, step := range t.steps {
+		var ok bool
+		if str, ok = step.fn(str); !ok {
+			return nil, nil
+		}
+	}
+
+	// Empty value is missing for non-string types
+	if _, ok := t.output.(*stringConverter); !ok && str == "" {
+		return nil, nil
+	}
+
+	return t.output.Convert(map[string]string{transformValue: str})
+}
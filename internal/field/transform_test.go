@@ -0,0 +1,165 @@
+package field
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestField_Transform(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		data  map[string]string
+		want  any
+	}{
+		{
+			name: "regex capture from another field",
+			field: Field{
+				Name:      "user_id",
+				Source:    "request",
+				Type:      "int",
+				Transform: []*Transform{{Regex: `/users/(\d+)`}},
+			},
+			data: map[string]string{"request": "GET /users/42/profile HTTP/1.1"},
+			want: int64(42),
+		},
+		{
+			name: "regex without groups",
+			field: Field{
+				Name:      "version",
+				Source:    "request",
+				Transform: []*Transform{{Regex: `HTTP/[\d.]+`}},
+			},
+			data: map[string]string{"request": "GET / HTTP/1.1"},
+			want: "HTTP/1.1",
+		},
+		{
+			name: "regex does not match",
+			field: Field{
+				Name:      "user_id",
+				Source:    "request",
+				Type:      "int",
+				Transform: []*Transform{{Regex: `/users/(\d+)`}},
+			},
+			data: map[string]string{"request": "GET / HTTP/1.1"},
+			want: nil,
+		},
+		{
+			name: "split with negative index",
+			field: Field{
+				Name:      "protocol",
+				Source:    "request",
+				Transform: []*Transform{{Split: " ", Index: -1}},
+			},
+			data: map[string]string{"request": "GET / HTTP/1.1"},
+			want: "HTTP/1.1",
+		},
+		{
+			name: "split out of range",
+			field: Field{
+				Name:      "protocol",
+				Source:    "request",
+				Transform: []*Transform{{Split: " ", Index: 5}},
+			},
+			data: map[string]string{"request": "GET / HTTP/1.1"},
+			want: nil,
+		},
+		{
+			name: "chain of transforms",
+			field: Field{
+				Name:   "search",
+				Source: "request",
+				Transform: []*Transform{
+					{Split: " ", Index: 1},
+					{Query: "q"},
+					{Replace: `\s+`, With: " "},
+					{Trim: true},
+					{Upper: true},
+				},
+			},
+			data: map[string]string{"request": "GET /search?page=2&q=+hello++world%21#top HTTP/1.1"},
+			want: "HELLO WORLD!",
+		},
+		{
+			name: "query parameter is missing",
+			field: Field{
+				Name:      "page",
+				Source:    "path",
+				Type:      "int",
+				Transform: []*Transform{{Query: "page"}},
+			},
+			data: map[string]string{"path": "/search?q=test"},
+			want: nil,
+		},
+		{
+			name: "url decode and lower",
+			field: Field{
+				Name:      "path",
+				Transform: []*Transform{{URLDecode: true}, {Lower: true}},
+			},
+			data: map[string]string{"path": "/Caf%C3%A9/%ZZ"},
+			want: "/caf%c3%a9/%zz",
+		},
+		{
+			name: "url decode",
+			field: Field{
+				Name:      "path",
+				Transform: []*Transform{{URLDecode: true}},
+			},
+			data: map[string]string{"path": "/Caf%C3%A9"},
+			want: "/Café",
+		},
+		{
+			name: "template with transform",
+			field: Field{
+				Name:      "status_class",
+				Template:  "{{.status}}",
+				Type:      "int",
+				Transform: []*Transform{{Regex: `^(\d)`}},
+			},
+			data: map[string]string{"status": "503"},
+			want: int64(5),
+		},
+		{
+			name: "missing source",
+			field: Field{
+				Name:      "path",
+				Transform: []*Transform{{Lower: true}},
+			},
+			data: map[string]string{},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := tt.field.Clone()
+			require.NoError(t, field.Init())
+
+			got, err := field.Convert(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTransform_Init(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+		err       string
+	}{
+		{"empty", Transform{}, "action is required"},
+		{"multiple actions", Transform{Lower: true, Trim: true}, "only one action is allowed, got lower, trim"},
+		{"invalid regex", Transform{Regex: "("}, "invalid regex"},
+		{"index without split", Transform{Index: 1}, "index requires split"},
+		{"with without replace", Transform{With: "x"}, "with requires replace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.transform.Init(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/input"
+	"github.com/fugo-app/fugo/internal/input/parser"
+	"github.com/fugo-app/fugo/pkg/duration"
+	"github.com/fugo-app/fugo/pkg/tailbuf"
+)
+
+// stderrTailSize is the size of stderr kept for the error message in "stream" mode.
+const stderrTailSize = 4 * 1024
+
+// CommandWatcher is an implementation of the command-based input.
+// It runs the shell command periodically and processes its output,
+// or keeps the long-running command and processes its output line by line.
+type CommandWatcher struct {
+	// Shell command to run. Executed with "sh -c".
+	// Example: "ss -s" or "/usr/local/bin/healthcheck.sh"
+	Command string `yaml:"command"`
+
+	// Run mode: "interval" or "stream"
+	// "interval" runs the command periodically and processes the whole output.
+	// "stream" keeps the command running and processes each line of the output.
+	// Default: "interval"
+	Mode string `yaml:"mode,omitempty"`
+
+	// Interval between runs. In "stream" mode, delay before restarting the exited command.
+	// Default: 60s for "interval" mode, 5s for "stream" mode
+	Interval string `yaml:"interval,omitempty"`
+
+	// Timeout for the single run in "interval" mode.
+	// Default: same as the interval
+	Timeout string `yaml:"timeout,omitempty"`
+
+	// Output format: "plain", "json", or "logfmt"
+	// The "json" format in "interval" mode accepts a single object, an array of objects,
+	// or a sequence of objects. Other formats are processed line by line.
+	// Default: "plain"
+	Format string `yaml:"format"`
+
+	// Regex to parse the plain output lines
+	// Example: `(?P<key>\w+):\s+(?P<value>\d+)`
+	Regex string `yaml:"regex,omitempty"`
+
+	stream    bool
+	json      bool
+	interval  time.Duration
+	timeout   time.Duration
+	parser    parser.Parser
+	processor input.Processor
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (cw *CommandWatcher) Init(processor input.Processor) error {
+	if cw.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+
+	mode := strings.ToLower(cw.Mode)
+	switch mode {
+	case "", "interval":
+		cw.stream = false
+		cw.interval = 60 * time.Second
+	case "stream":
+		cw.stream = true
+		cw.interval = 5 * time.Second
+	default:
+		return fmt.Errorf("unsupported mode: %s", mode)
+	}
+
+	if cw.Interval != "" {
+		d, err := duration.Parse(cw.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("interval should be positive")
+		}
+		cw.interval = d
+	}
+
+	cw.timeout = cw.interval
+	if cw.Timeout != "" {
+		d, err := duration.Parse(cw.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout value: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout should be positive")
+		}
+		cw.timeout = d
+	}
+
+	if p, err := parser.New(cw.Format, cw.Regex); err != nil {
+		return err
+	} else {
+		cw.parser = p
+	}
+
+	cw.json = strings.EqualFold(cw.Format, "json")
+	cw.processor = processor
+
+	return nil
+}
+
+func (cw *CommandWatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cw.cancel = cancel
+	cw.done = make(chan struct{})
+
+	if cw.stream {
+		go cw.watchStream(ctx)
+	} else {
+		go cw.watchInterval(ctx)
+	}
+}
+
+func (cw *CommandWatcher) Stop() {
+	if cw.cancel != nil {
+		cw.cancel()
+		<-cw.done
+	}
+}
+
+func (cw *CommandWatcher) watchInterval(ctx context.Context) {
+	defer close(cw.done)
+
+	cw.run(ctx)
+
+	ticker := time.NewTicker(cw.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cw.run(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cw *CommandWatcher) watchStream(ctx context.Context) {
+	defer close(cw.done)
+
+	for {
+		if err := cw.runStream(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("command failed (%s): %v", cw.Command, err)
+		}
+
+		select {
+		case <-time.After(cw.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (cw *CommandWatcher) command(ctx context.Context, stderr io.Writer) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", cw.Command)
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// run executes the command once and processes the whole output.
+func (cw *CommandWatcher) run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, cw.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := cw.command(ctx, &stderr)
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+
+		log.Printf("command failed (%s): %v", cw.Command, err)
+		for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+			if line != "" {
+				log.Print(line)
+			}
+		}
+		// Output of the failed command is still processed,
+		// health scripts may report details with non-zero exit code.
+	}
+
+	if cw.json {
+		cw.processJson(output)
+	} else {
+		cw.processLines(bytes.NewReader(output))
+	}
+}
+
+// runStream executes the long-running command and processes the output line by line.
+func (cw *CommandWatcher) runStream(ctx context.Context) error {
+	// Long-running command could write to stderr indefinitely, keep only the tail for the error
+	stderr := tailbuf.NewTailBuffer(stderrTailSize)
+	cmd := cw.command(ctx, stderr)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+
+	cw.processLines(stdout)
+
+	if err := cmd.Wait(); err != nil {
+		text := strings.TrimSpace(stderr.String())
+		if text != "" {
+			return fmt.Errorf("%w: %s", err, text)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (cw *CommandWatcher) processLines(r io.Reader) {
+	var offset int64
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		lineOffset := offset
+		offset += int64(len(line))
+
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			cw.process(lineOffset, line)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+}
+
+func (cw *CommandWatcher) processJson(output []byte) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		offset := decoder.InputOffset()
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			if err != io.EOF {
+				cw.processor.Reject(cw.Command, offset, string(output[offset:]), err)
+			}
+			return
+		}
+
+		value = bytes.TrimSpace(value)
+		if len(value) > 0 && value[0] == '[' {
+			var items []json.RawMessage
+			if err := json.Unmarshal(value, &items); err != nil {
+				cw.processor.Reject(cw.Command, offset, string(value), err)
+				continue
+			}
+
+			for _, item := range items {
+				cw.process(offset, string(item))
+			}
+		} else {
+			cw.process(offset, string(value))
+		}
+	}
+}
+
+func (cw *CommandWatcher) process(offset int64, text string) {
+	raw, err := cw.parser.Parse(text)
+	if err != nil {
+		cw.processor.Reject(cw.Command, offset, text, err)
+		return
+	}
+
+	if raw == nil {
+		cw.processor.Reject(cw.Command, offset, text, parser.ErrNoMatch)
+		return
+	}
+
+	data, err := cw.processor.Serialize(raw)
+	if err != nil {
+		cw.processor.Reject(cw.Command, offset, text, err)
+	}
+
+	if data != nil {
+		cw.processor.Write(data)
+	}
+}
@@ -0,0 +1,189 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockProcessor struct {
+	mu        sync.Mutex
+	processed []map[string]any
+	rejected  []string
+}
+
+func (p *mockProcessor) Serialize(data map[string]string) (map[string]any, error) {
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (p *mockProcessor) Write(data map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed = append(p.processed, data)
+}
+
+func (p *mockProcessor) Reject(source string, offset int64, line string, reason error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejected = append(p.rejected, line)
+}
+
+func (p *mockProcessor) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.processed)
+}
+
+func TestCommandWatcher_run(t *testing.T) {
+	tests := []struct {
+		name     string
+		watcher  *CommandWatcher
+		want     []map[string]any
+		rejected []string
+	}{
+		{
+			name: "plain format",
+			watcher: &CommandWatcher{
+				Command: `printf 'TCP: 10\nUDP: 5\nTotal\n'`,
+				Regex:   `^(?P<key>\w+):\s+(?P<value>\d+)$`,
+			},
+			want: []map[string]any{
+				{"key": "TCP", "value": "10"},
+				{"key": "UDP", "value": "5"},
+			},
+			rejected: []string{"Total"},
+		},
+		{
+			name: "logfmt format",
+			watcher: &CommandWatcher{
+				Command: `echo 'status=ok latency=12'`,
+				Format:  "logfmt",
+			},
+			want: []map[string]any{
+				{"status": "ok", "latency": "12"},
+			},
+		},
+		{
+			name: "json object",
+			watcher: &CommandWatcher{
+				Command: `printf '{\n  "gpu": "0",\n  "temp": 45\n}\n'`,
+				Format:  "json",
+			},
+			want: []map[string]any{
+				{"gpu": "0", "temp": "45"},
+			},
+		},
+		{
+			name: "json array and sequence",
+			watcher: &CommandWatcher{
+				Command: `echo '[{"gpu":"0"},{"gpu":"1"}] {"gpu":"2"}'`,
+				Format:  "json",
+			},
+			want: []map[string]any{
+				{"gpu": "0"},
+				{"gpu": "1"},
+				{"gpu": "2"},
+			},
+		},
+		{
+			name: "failed command output",
+			watcher: &CommandWatcher{
+				Command: `echo 'status=fail'; exit 1`,
+				Format:  "logfmt",
+			},
+			want: []map[string]any{
+				{"status": "fail"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &mockProcessor{}
+			require.NoError(t, tt.watcher.Init(processor), "Failed to init watcher")
+
+			tt.watcher.run(context.Background())
+
+			require.Equal(t, tt.want, processor.processed, "Processed data doesn't match")
+			require.Equal(t, tt.rejected, processor.rejected, "Rejected lines don't match")
+		})
+	}
+}
+
+func TestCommandWatcher_Timeout(t *testing.T) {
+	processor := &mockProcessor{}
+	watcher := &CommandWatcher{
+		Command: `echo 'status=started'; sleep 5; echo 'status=finished'`,
+		Format:  "logfmt",
+		Timeout: "1s",
+	}
+	require.NoError(t, watcher.Init(processor), "Failed to init watcher")
+
+	started := time.Now()
+	watcher.run(context.Background())
+	require.Less(t, time.Since(started), 4*time.Second, "Command should be killed by timeout")
+
+	require.Equal(t, []map[string]any{{"status": "started"}}, processor.processed)
+}
+
+func TestCommandWatcher_Stream(t *testing.T) {
+	processor := &mockProcessor{}
+	watcher := &CommandWatcher{
+		Command: `echo 'n=1'; echo 'n=2'; sleep 10`,
+		Mode:    "stream",
+		Format:  "logfmt",
+	}
+	require.NoError(t, watcher.Init(processor), "Failed to init watcher")
+
+	watcher.Start()
+
+	require.Eventually(t, func() bool {
+		return processor.count() == 2
+	}, 2*time.Second, 50*time.Millisecond, "Lines should be processed while command is running")
+
+	watcher.Stop()
+
+	expected := []map[string]any{
+		{"n": "1"},
+		{"n": "2"},
+	}
+	require.Equal(t, expected, processor.processed, "Processed data doesn't match")
+}
+
+func TestCommandWatcher_StreamStderr(t *testing.T) {
+	watcher := &CommandWatcher{
+		Command: `yes x | head -c 100000 >&2; echo failed >&2; exit 1`,
+		Mode:    "stream",
+		Format:  "logfmt",
+	}
+	require.NoError(t, watcher.Init(&mockProcessor{}), "Failed to init watcher")
+
+	err := watcher.runStream(context.Background())
+	require.ErrorContains(t, err, "exit status 1")
+	require.True(t, strings.HasSuffix(err.Error(), "failed"), "Stderr tail should be kept")
+	require.Less(t, len(err.Error()), stderrTailSize+100, "Stderr should be limited")
+}
+
+func TestCommandWatcher_Init(t *testing.T) {
+	processor := &mockProcessor{}
+
+	require.Error(t, (&CommandWatcher{}).Init(processor), "Command is required")
+	require.Error(t, (&CommandWatcher{Command: "true", Mode: "cron", Format: "json"}).Init(processor), "Invalid mode")
+	require.Error(t, (&CommandWatcher{Command: "true", Format: "plain"}).Init(processor), "Regex is required")
+	require.ErrorContains(t, (&CommandWatcher{Command: "true", Format: "json", Interval: "0s"}).Init(processor), "interval should be positive")
+	require.ErrorContains(t, (&CommandWatcher{Command: "true", Format: "json", Mode: "stream", Interval: "0"}).Init(processor), "interval should be positive")
+	require.ErrorContains(t, (&CommandWatcher{Command: "true", Format: "json", Timeout: "0s"}).Init(processor), "timeout should be positive")
+
+	watcher := &CommandWatcher{Command: "true", Format: "json", Interval: "10s"}
+	require.NoError(t, watcher.Init(processor))
+	require.Equal(t, 10*time.Second, watcher.interval)
+	require.Equal(t, 10*time.Second, watcher.timeout)
+}
@@ -0,0 +1,198 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// fileEncoding describes the character encoding of a log file.
+// Lines are split in the source encoding, so offsets always count the source bytes,
+// and decoded into UTF-8 before parsing.
+type fileEncoding struct {
+	name      string
+	unit      int  // size of the code unit in bytes: 1 or 2
+	bigEndian bool // byte order for 2-byte code units
+	charset   encoding.Encoding
+}
+
+var (
+	encodingUTF8 = &fileEncoding{
+		name: "utf-8",
+		unit: 1,
+	}
+	encodingUTF16LE = &fileEncoding{
+		name:    "utf-16le",
+		unit:    2,
+		charset: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	}
+	encodingUTF16BE = &fileEncoding{
+		name:      "utf-16be",
+		unit:      2,
+		bigEndian: true,
+		charset:   unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	}
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// newFileEncoding returns the encoding by its name.
+// Supports "utf-8" (default), "utf-16" (little-endian unless BOM says otherwise),
+// "utf-16le", "utf-16be", and any ASCII-compatible encoding known by WHATWG,
+// for example "latin1", "cp1251", "windows-1252", "koi8-r", "shift_jis".
+func newFileEncoding(name string) (*fileEncoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	switch name {
+	case "", "utf-8", "utf8":
+		return encodingUTF8, nil
+	case "utf-16", "utf16", "utf-16le", "utf16le":
+		return encodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return encodingUTF16BE, nil
+	}
+
+	charset, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding: %s", name)
+	}
+
+	canonical, _ := htmlindex.Name(charset)
+	switch canonical {
+	case "utf-8":
+		return encodingUTF8, nil
+	case "utf-16le":
+		return encodingUTF16LE, nil
+	case "utf-16be":
+		return encodingUTF16BE, nil
+	}
+
+	return &fileEncoding{
+		name:    canonical,
+		unit:    1,
+		charset: charset,
+	}, nil
+}
+
+// detectBOM checks the beginning of the file for the byte order mark.
+// Returns the encoding defined by the BOM and the BOM size in bytes,
+// or the current encoding and 0 if the file has no BOM.
+// Legacy single-byte encodings are never overridden, because the BOM bytes
+// are valid characters there.
+func (e *fileEncoding) detectBOM(head []byte) (*fileEncoding, int) {
+	if e != nil && e.unit == 1 && e.charset != nil {
+		return e, 0
+	}
+
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		return encodingUTF8, len(bomUTF8)
+	case bytes.HasPrefix(head, bomUTF16LE):
+		return encodingUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(head, bomUTF16BE):
+		return encodingUTF16BE, len(bomUTF16BE)
+	default:
+		return e, 0
+	}
+}
+
+// align moves the offset to the beginning of the next code unit.
+func (e *fileEncoding) align(offset int64) int64 {
+	if e == nil || e.unit == 1 {
+		return offset
+	}
+
+	if rem := offset % int64(e.unit); rem != 0 {
+		offset += int64(e.unit) - rem
+	}
+
+	return offset
+}
+
+// readLine reads bytes until the newline in the source encoding.
+// Same as bufio.Reader.ReadBytes, the line includes the newline
+// and err is not nil if and only if the line does not end with the newline.
+func (e *fileEncoding) readLine(reader *bufio.Reader) ([]byte, error) {
+	if e == nil || e.unit == 1 {
+		return reader.ReadBytes('\n')
+	}
+
+	var line []byte
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		line = append(line, chunk...)
+		if err != nil {
+			return line, err
+		}
+
+		if e.bigEndian {
+			// "\x00\n" aligned to the code unit
+			size := len(line)
+			if size%2 == 0 && line[size-2] == 0 {
+				return line, nil
+			}
+		} else if len(line)%2 == 1 {
+			// "\n\x00" aligned to the code unit
+			next, err := reader.ReadByte()
+			if err != nil {
+				return line, err
+			}
+			line = append(line, next)
+			if next == 0 {
+				return line, nil
+			}
+		}
+	}
+}
+
+// hasNewline checks if the line ends with the newline in the source encoding.
+func (e *fileEncoding) hasNewline(line []byte) bool {
+	if e == nil || e.unit == 1 {
+		return bytes.HasSuffix(line, []byte("\n"))
+	}
+
+	if e.bigEndian {
+		return bytes.HasSuffix(line, []byte("\x00\n"))
+	} else {
+		return bytes.HasSuffix(line, []byte("\n\x00"))
+	}
+}
+
+// trimNewline removes the trailing newline and carriage return.
+func (e *fileEncoding) trimNewline(line []byte) []byte {
+	if e == nil || e.unit == 1 {
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		return bytes.TrimSuffix(line, []byte("\r"))
+	}
+
+	if e.bigEndian {
+		line = bytes.TrimSuffix(line, []byte("\x00\n"))
+		return bytes.TrimSuffix(line, []byte("\x00\r"))
+	} else {
+		line = bytes.TrimSuffix(line, []byte("\n\x00"))
+		return bytes.TrimSuffix(line, []byte("\r\x00"))
+	}
+}
+
+// decode converts the line from the source encoding to UTF-8.
+func (e *fileEncoding) decode(line []byte) (string, error) {
+	if e == nil || e.charset == nil {
+		return string(line), nil
+	}
+
+	out, err := e.charset.NewDecoder().Bytes(line)
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", e.name, err)
+	}
+
+	return string(out), nil
+}
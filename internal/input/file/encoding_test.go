@@ -0,0 +1,199 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeUTF16(text string, bigEndian bool) []byte {
+	units := utf16.Encode([]rune(text))
+	out := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		if bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+	return out
+}
+
+func TestNewFileEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: "utf-8"},
+		{name: "UTF-8", want: "utf-8"},
+		{name: "utf-16", want: "utf-16le"},
+		{name: "utf-16be", want: "utf-16be"},
+		{name: "latin1", want: "windows-1252"},
+		{name: "cp1251", want: "windows-1251"},
+		{name: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := newFileEncoding(tt.name)
+			if tt.wantErr {
+				require.Error(t, err, "Expected error but got none")
+			} else {
+				require.NoError(t, err, "Unexpected error")
+				require.Equal(t, tt.want, enc.name, "Unexpected encoding")
+			}
+		})
+	}
+}
+
+func TestFileEncoding_readLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding *fileEncoding
+		data     []byte
+		want     []string
+	}{
+		{
+			name:     "utf-8",
+			encoding: encodingUTF8,
+			data:     []byte("line1\r\nline2\nincomplete"),
+			want:     []string{"line1", "line2"},
+		},
+		{
+			name:     "utf-16le",
+			encoding: encodingUTF16LE,
+			// U+0A0A contains newline bytes in both halves
+			data: encodeUTF16("ਊline1\r\nпривет\nincomplete", false),
+			want: []string{"ਊline1", "привет"},
+		},
+		{
+			name:     "utf-16be",
+			encoding: encodingUTF16BE,
+			data:     encodeUTF16("ਊline1\r\nпривет\nincomplete", true),
+			want:     []string{"ਊline1", "привет"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(bytes.NewReader(tt.data))
+
+			var lines []string
+			var offset int
+			for {
+				line, err := tt.encoding.readLine(reader)
+				if !tt.encoding.hasNewline(line) {
+					require.Equal(t, io.EOF, err, "Incomplete line should end with EOF")
+					break
+				}
+				require.NoError(t, err, "Unexpected error")
+
+				offset += len(line)
+
+				text, err := tt.encoding.decode(tt.encoding.trimNewline(line))
+				require.NoError(t, err, "Failed to decode line")
+				lines = append(lines, text)
+			}
+
+			require.Equal(t, tt.want, lines, "Lines not equal")
+			require.Zero(t, offset%tt.encoding.unit, "Offset should be aligned")
+		})
+	}
+}
+
+func TestFileEncoding_decode(t *testing.T) {
+	enc, err := newFileEncoding("cp1251")
+	require.NoError(t, err, "Failed to create encoding")
+
+	text, err := enc.decode([]byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2})
+	require.NoError(t, err, "Failed to decode")
+	require.Equal(t, "Привет", text)
+
+	enc, err = newFileEncoding("latin1")
+	require.NoError(t, err, "Failed to create encoding")
+
+	text, err = enc.decode([]byte("caf\xe9"))
+	require.NoError(t, err, "Failed to decode")
+	require.Equal(t, "café", text)
+}
+
+func TestFileEncoding_detectBOM(t *testing.T) {
+	enc, size := encodingUTF8.detectBOM([]byte{0xFF, 0xFE, 'a'})
+	require.Equal(t, encodingUTF16LE, enc)
+	require.Equal(t, 2, size)
+
+	enc, size = encodingUTF16LE.detectBOM([]byte{0xFE, 0xFF, 0})
+	require.Equal(t, encodingUTF16BE, enc)
+	require.Equal(t, 2, size)
+
+	enc, size = encodingUTF8.detectBOM([]byte{0xEF, 0xBB, 0xBF})
+	require.Equal(t, encodingUTF8, enc)
+	require.Equal(t, 3, size)
+
+	enc, size = encodingUTF8.detectBOM([]byte("abc"))
+	require.Equal(t, encodingUTF8, enc)
+	require.Equal(t, 0, size)
+
+	// "яю" in cp1251 looks like UTF-16LE BOM
+	cp1251, err := newFileEncoding("cp1251")
+	require.NoError(t, err, "Failed to create encoding")
+	enc, size = cp1251.detectBOM([]byte{0xFF, 0xFE, 'a'})
+	require.Equal(t, cp1251, enc)
+	require.Equal(t, 0, size)
+}
+
+func TestFileWorker_tail_Encoding(t *testing.T) {
+	mockParser := &mockParser{}
+	mockProcessor := &mockProcessor{}
+
+	tempDir := t.TempDir()
+	tempFile := filepath.Join(tempDir, "test.log")
+
+	globalFileConfig := &FileConfig{}
+	globalFileConfig.InitDefault(tempDir)
+	require.NoError(t, globalFileConfig.Open(), "failed to open file config")
+	defer globalFileConfig.Close()
+
+	// UTF-16LE with BOM, encoding is not configured
+	bom := []byte{0xFF, 0xFE}
+	data := encodeUTF16("первая\r\nвторая\n", false)
+	require.NoError(t, os.WriteFile(tempFile, append(bom, data...), 0644))
+
+	worker, err := newFileWorker(tempFile, nil, mockParser, nil, nil, mockProcessor)
+	require.NoError(t, err, "Failed to create file worker")
+
+	worker.tail()
+
+	expected := []map[string]any{
+		{"line": "первая"},
+		{"line": "вторая"},
+	}
+	require.Equal(t, expected, mockProcessor.processed, "Processed data doesn't match")
+	require.Equal(t, int64(len(bom)+len(data)), getOffset(tempFile), "Offset should count source bytes")
+
+	// Append one more line in two writes
+	{
+		f, err := os.OpenFile(tempFile, os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err, "Failed to open file for appending")
+		_, err = f.Write(encodeUTF16("трет", false))
+		require.NoError(t, err, "Failed to append to file")
+		_, err = f.Write(encodeUTF16("ья\n", false))
+		require.NoError(t, err, "Failed to append to file")
+		f.Close()
+	}
+
+	mockProcessor.processed = nil
+	worker.tail()
+
+	expected = []map[string]any{
+		{"line": "третья"},
+	}
+	require.Equal(t, expected, mockProcessor.processed, "Processed data doesn't match")
+}
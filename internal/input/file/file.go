@@ -1,16 +1,10 @@
 package file
 
 import (
-	"fmt"
-	"log"
 	"os"
 	"path/filepath"
-	"sync"
-	"time"
 
-	"gopkg.in/yaml.v3"
-
-	"github.com/fugo-app/fugo/pkg/debounce"
+	"github.com/fugo-app/fugo/internal/offsets"
 )
 
 type FileConfig struct {
@@ -20,11 +14,6 @@ type FileConfig struct {
 
 	// Limit the number of lines to read from the file on first read.
 	Limit int `yaml:"limit,omitempty"`
-
-	mutex   sync.Mutex
-	offsets map[string]int64
-
-	debounce *debounce.Debounce
 }
 
 var globalFileConfig *FileConfig
@@ -34,50 +23,21 @@ func (fc *FileConfig) InitDefault(dir string) {
 	fc.Limit = 100
 }
 
+// Open loads the offset storage shared with other inputs.
 func (fc *FileConfig) Open() error {
 	globalFileConfig = fc
 
-	if fc.Offsets != "" {
-		dir := filepath.Dir(fc.Offsets)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("create offsets directory: %w", err)
-		}
-
-		// Load existing offsets
-		data, err := os.ReadFile(fc.Offsets)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				return fmt.Errorf("read offsets file: %w", err)
-			}
-		} else {
-			if err := yaml.Unmarshal(data, &fc.offsets); err != nil {
-				return fmt.Errorf("unmarshal offsets: %w", err)
-			}
-		}
-	}
-
-	if fc.offsets == nil {
-		fc.offsets = make(map[string]int64)
-	}
-
-	fc.debounce = debounce.NewDebounce(fc.save, time.Second, false)
-	fc.debounce.Start()
-
-	return nil
+	return offsets.Open(fc.Offsets)
 }
 
 func (fc *FileConfig) Close() error {
-	fc.debounce.Stop()
-	fc.save()
+	offsets.Close()
 
 	return nil
 }
 
 func (fc *FileConfig) getOffset(path string) int64 {
-	fc.mutex.Lock()
-	defer fc.mutex.Unlock()
-
-	if offset, ok := fc.offsets[path]; ok {
+	if offset, ok := offsets.GetOffset(path); ok {
 		return offset
 	}
 
@@ -89,48 +49,12 @@ func (fc *FileConfig) getOffset(path string) int64 {
 	return getFileOffset(path, fc.Limit)
 }
 
-func (fc *FileConfig) setOffset(path string, offset int64) {
-	fc.mutex.Lock()
-	defer fc.mutex.Unlock()
-
-	fc.offsets[path] = offset
-	fc.debounce.Emit()
-}
-
-func (fc *FileConfig) prepare() []byte {
-	fc.mutex.Lock()
-	defer fc.mutex.Unlock()
-
-	out, err := yaml.Marshal(&fc.offsets)
-	if err != nil {
-		log.Printf("Error marshalling offsets: %v", err)
-		out = nil
-	}
-
-	return out
-}
-
-func (fc *FileConfig) save() {
-	if fc.Offsets == "" {
-		return
-	}
-
-	data := fc.prepare()
-	if data == nil {
-		return
-	}
-
-	if err := os.WriteFile(fc.Offsets, data, 0644); err != nil {
-		log.Printf("Error writing offsets to file: %v", err)
-	}
-}
-
 func getOffset(path string) int64 {
 	return globalFileConfig.getOffset(path)
 }
 
 func setOffset(path string, offset int64) {
-	globalFileConfig.setOffset(path, offset)
+	offsets.SetOffset(path, offset)
 }
 
 func getFileOffset(path string, lines int) int64 {
@@ -109,3 +109,21 @@ func TestGetFileOffset_NonExistentFile(t *testing.T) {
 	result := getFileOffset("/non/existent/file.txt", 10)
 	require.Equal(t, int64(0), result)
 }
+
+func TestFileConfig_SaveLoad(t *testing.T) {
+	tempDir := t.TempDir()
+
+	fc := &FileConfig{}
+	fc.InitDefault(tempDir)
+	require.NoError(t, fc.Open(), "failed to open file config")
+
+	setOffset("/var/log/app.log", 1024)
+	require.NoError(t, fc.Close(), "failed to close file config")
+
+	fc = &FileConfig{}
+	fc.InitDefault(tempDir)
+	require.NoError(t, fc.Open(), "failed to open file config")
+	defer fc.Close()
+
+	require.Equal(t, int64(1024), getOffset("/var/log/app.log"))
+}
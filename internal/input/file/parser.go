@@ -1,5 +0,0 @@
-package file
-
-type fileParser interface {
-	Parse(line string) (map[string]string, error)
-}
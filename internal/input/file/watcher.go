@@ -11,6 +11,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/fugo-app/fugo/internal/input"
+	"github.com/fugo-app/fugo/internal/input/parser"
 )
 
 // FileWatcher is an implementation of the file-based log agent.
@@ -21,7 +22,7 @@ type FileWatcher struct {
 	// For example: `/var/log/nginx/access_(?P<host>.*)\.log`
 	Path string `yaml:"path"`
 
-	// Log format to parse the log file: "plain" or "json"
+	// Log format to parse the log file: "plain", "json", or "logfmt"
 	// Default: "plain"
 	Format string `yaml:"format"`
 
@@ -29,12 +30,19 @@ type FileWatcher struct {
 	// Example: `(?P<time>[^ ]+) (?P<level>[^ ]+) (?P<message>.*)`
 	Regex string `yaml:"regex,omitempty"`
 
+	// Character encoding of the log file. Lines are converted to UTF-8 before parsing.
+	// Byte order mark at the beginning of the file overrides UTF-8 and UTF-16 encodings.
+	// Default: "utf-8"
+	// Example: "utf-16le", "utf-16be", "latin1", "cp1251"
+	Encoding string `yaml:"encoding,omitempty"`
+
 	// File rotation
 	Rotate *RotationConfig `yaml:"rotate,omitempty"`
 
 	dir       string         // Base directory for the path
 	re        *regexp.Regexp // Regex to match the file name
-	parser    fileParser     // Line parser
+	parser    parser.Parser  // Line parser
+	encoding  *fileEncoding  // Source encoding
 	processor input.Processor
 	workers   map[string]*fileWorker
 
@@ -50,31 +58,16 @@ func (fw *FileWatcher) Init(processor input.Processor) error {
 		return fmt.Errorf("path must be absolute: %s", fw.Path)
 	}
 
-	format := strings.ToLower(fw.Format)
-	if format == "" {
-		format = "plain"
+	if p, err := parser.New(fw.Format, fw.Regex); err != nil {
+		return err
+	} else {
+		fw.parser = p
 	}
 
-	if format == "plain" {
-		if fw.Regex == "" {
-			return fmt.Errorf("regex is required for plain format")
-		}
-
-		p, err := newPlainParser(fw.Regex)
-		if err != nil {
-			return fmt.Errorf("plain parser: %w", err)
-		} else {
-			fw.parser = p
-		}
-	} else if format == "json" {
-		p, err := newJsonParser()
-		if err != nil {
-			return fmt.Errorf("json parser: %w", err)
-		} else {
-			fw.parser = p
-		}
+	if enc, err := newFileEncoding(fw.Encoding); err != nil {
+		return err
 	} else {
-		return fmt.Errorf("unsupported format: %s", format)
+		fw.encoding = enc
 	}
 
 	dir, pattern := filepath.Split(fw.Path)
@@ -131,7 +124,7 @@ func (fw *FileWatcher) startWorker(path string, watcher *fsnotify.Watcher) {
 		data[name] = match[i]
 	}
 
-	worker, err := newFileWorker(path, data, fw.parser, fw.Rotate, fw.processor)
+	worker, err := newFileWorker(path, data, fw.parser, fw.encoding, fw.Rotate, fw.processor)
 	if err != nil {
 		log.Printf("failed to create worker (%s): %v", path, err)
 		return
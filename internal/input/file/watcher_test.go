@@ -11,10 +11,11 @@ import (
 
 type dummyProcessor struct{}
 
-func (d *dummyProcessor) Serialize(data map[string]string) map[string]any {
-	return nil
+func (d *dummyProcessor) Serialize(data map[string]string) (map[string]any, error) {
+	return nil, nil
 }
 func (d *dummyProcessor) Write(data map[string]any) {}
+func (d *dummyProcessor) Reject(source string, offset int64, line string, reason error) {}
 
 func TestFileWatcher_WorkerManagement(t *testing.T) {
 	// Create a temporary directory for the test
@@ -2,7 +2,6 @@ package file
 
 import (
 	"bufio"
-	"bytes"
 	"io"
 	"log"
 	"maps"
@@ -10,13 +9,15 @@ import (
 	"time"
 
 	"github.com/fugo-app/fugo/internal/input"
+	"github.com/fugo-app/fugo/internal/input/parser"
 	"github.com/fugo-app/fugo/pkg/debounce"
 )
 
 type fileWorker struct {
 	path      string
 	ext       map[string]string
-	parser    fileParser
+	parser    parser.Parser
+	encoding  *fileEncoding
 	rotator   fileRotator
 	processor input.Processor
 
@@ -27,7 +28,8 @@ type fileWorker struct {
 func newFileWorker(
 	path string,
 	ext map[string]string,
-	parser fileParser,
+	parser parser.Parser,
+	encoding *fileEncoding,
 	rotator fileRotator,
 	processor input.Processor,
 ) (*fileWorker, error) {
@@ -35,9 +37,10 @@ func newFileWorker(
 		path:      path,
 		ext:       ext,
 		parser:    parser,
+		encoding:  encoding,
 		rotator:   rotator,
 		processor: processor,
-		offset:    getOffset(path),
+		offset:    encoding.align(getOffset(path)),
 		debounce:  nil,
 	}, nil
 }
@@ -84,6 +87,17 @@ func (fw *fileWorker) tail() {
 		offset = 0
 	}
 
+	// Byte order mark overrides the configured encoding
+	encoding := fw.encoding
+	head := make([]byte, len(bomUTF8))
+	if n, _ := file.ReadAt(head, 0); n > 0 {
+		var bom int
+		encoding, bom = encoding.detectBOM(head[:n])
+		if offset < int64(bom) {
+			offset = int64(bom)
+		}
+	}
+
 	_, err = file.Seek(offset, 0)
 	if err != nil {
 		return
@@ -91,30 +105,25 @@ func (fw *fileWorker) tail() {
 
 	reader := bufio.NewReaderSize(file, 64*1024)
 	for {
-		line, err := reader.ReadBytes('\n')
+		line, err := encoding.readLine(reader)
 		if err != nil && err != io.EOF {
 			break
 		}
 
-		if !bytes.HasSuffix(line, []byte("\n")) {
+		if !encoding.hasNewline(line) {
 			break
 		}
 
+		lineOffset := offset
 		offset += int64(len(line))
 
-		line = line[:len(line)-1]
-		if bytes.HasSuffix(line, []byte("\r")) {
-			line = line[:len(line)-1]
-		}
+		line = encoding.trimNewline(line)
 
 		if len(line) > 0 {
-			text := string(line)
-
-			if raw, err := fw.parser.Parse(text); err == nil {
-				maps.Copy(raw, fw.ext)
-				if data := fw.processor.Serialize(raw); data != nil {
-					fw.processor.Write(data)
-				}
+			if text, err := encoding.decode(line); err != nil {
+				fw.processor.Reject(fw.path, lineOffset, string(line), err)
+			} else {
+				fw.process(lineOffset, text)
 			}
 		}
 
@@ -139,3 +148,27 @@ func (fw *fileWorker) tail() {
 		}
 	}
 }
+
+func (fw *fileWorker) process(offset int64, text string) {
+	raw, err := fw.parser.Parse(text)
+	if err != nil {
+		fw.processor.Reject(fw.path, offset, text, err)
+		return
+	}
+
+	if raw == nil {
+		fw.processor.Reject(fw.path, offset, text, parser.ErrNoMatch)
+		return
+	}
+
+	maps.Copy(raw, fw.ext)
+
+	data, err := fw.processor.Serialize(raw)
+	if err != nil {
+		fw.processor.Reject(fw.path, offset, text, err)
+	}
+
+	if data != nil {
+		fw.processor.Write(data)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/fugo-app/fugo/internal/input/parser"
 )
 
 type mockParser struct {
@@ -25,14 +27,15 @@ func (p *mockParser) Parse(text string) (map[string]string, error) {
 type mockProcessor struct {
 	mu        sync.Mutex
 	processed []map[string]any
+	rejected  []string
 }
 
-func (p *mockProcessor) Serialize(data map[string]string) map[string]any {
+func (p *mockProcessor) Serialize(data map[string]string) (map[string]any, error) {
 	result := make(map[string]any, len(data))
 	for k, v := range data {
 		result[k] = v
 	}
-	return result
+	return result, nil
 }
 
 func (p *mockProcessor) Write(data map[string]any) {
@@ -41,6 +44,12 @@ func (p *mockProcessor) Write(data map[string]any) {
 	p.processed = append(p.processed, data)
 }
 
+func (p *mockProcessor) Reject(source string, offset int64, line string, reason error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejected = append(p.rejected, line)
+}
+
 func TestFileWorker_tail(t *testing.T) {
 	// Create mocks
 	mockParser := &mockParser{}
@@ -73,6 +82,7 @@ func TestFileWorker_tail(t *testing.T) {
 		},
 		mockParser,
 		nil,
+		nil,
 		mockProcessor,
 	)
 	require.NoError(t, err, "Failed to create file worker")
@@ -150,3 +160,38 @@ func TestFileWorker_tail(t *testing.T) {
 	}
 	require.Equal(t, expected, mockProcessor.processed, "Processed data doesn't match")
 }
+
+func TestFileWorker_tail_Reject(t *testing.T) {
+	mockProcessor := &mockProcessor{}
+
+	tempDir := t.TempDir()
+	tempFile := filepath.Join(tempDir, "test.log")
+
+	globalFileConfig := &FileConfig{}
+	globalFileConfig.InitDefault(tempDir)
+	require.NoError(t, globalFileConfig.Open(), "failed to open file config")
+	defer globalFileConfig.Close()
+
+	testData := "INFO first\nunexpected line\nWARN second\n"
+	require.NoError(t, os.WriteFile(tempFile, []byte(testData), 0644))
+
+	plainParser, err := parser.New("plain", `^(?P<level>[A-Z]+) (?P<message>.*)$`)
+	require.NoError(t, err, "Failed to create parser")
+
+	worker, err := newFileWorker(
+		tempFile,
+		map[string]string{
+			"source": "test",
+		},
+		plainParser,
+		nil,
+		nil,
+		mockProcessor,
+	)
+	require.NoError(t, err, "Failed to create file worker")
+
+	worker.tail()
+
+	require.Len(t, mockProcessor.processed, 2, "Processor should process 2 lines")
+	require.Equal(t, []string{"unexpected line"}, mockProcessor.rejected, "Rejected lines don't match")
+}
@@ -0,0 +1,293 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/input"
+	"github.com/fugo-app/fugo/internal/offsets"
+	"github.com/fugo-app/fugo/pkg/tailbuf"
+)
+
+// JournalWatcher is an implementation of the systemd journal input.
+// It follows the journal with journalctl in the export format
+// and resumes from the last processed cursor after restart.
+//
+// Each entry provides all journal fields as is (e.g. "_SYSTEMD_UNIT", "MESSAGE")
+// and the following aliases: "time" (unix milliseconds), "unit", "priority" (0-7),
+// "level" (e.g. "err"), "message", "pid", "hostname", "identifier".
+type JournalWatcher struct {
+	// Systemd units to read. Glob patterns are supported.
+	// Default: all units
+	// Example: ["nginx.service", "postgresql@*.service"]
+	Units []string `yaml:"units,omitempty"`
+
+	// Number of recent entries to read on first start.
+	// Default: 100
+	Limit int `yaml:"limit,omitempty"`
+
+	processor input.Processor
+	key       string // Key to store the cursor
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+const restartDelay = 5 * time.Second
+
+// stderrTailSize is the size of journalctl stderr kept for the error message.
+const stderrTailSize = 4 * 1024
+
+var levelNames = []string{
+	"emerg",
+	"alert",
+	"crit",
+	"err",
+	"warning",
+	"notice",
+	"info",
+	"debug",
+}
+
+var fieldAliases = map[string]string{
+	"MESSAGE":           "message",
+	"_PID":              "pid",
+	"_HOSTNAME":         "hostname",
+	"SYSLOG_IDENTIFIER": "identifier",
+}
+
+// Maximum size of the binary field
+const maxFieldSize = 16 * 1024 * 1024
+
+// journalctl is the command to read the journal.
+var journalctl = "journalctl"
+
+func (jw *JournalWatcher) Init(processor input.Processor) error {
+	for _, unit := range jw.Units {
+		if unit == "" {
+			return fmt.Errorf("unit name is required")
+		}
+	}
+
+	if jw.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d", jw.Limit)
+	} else if jw.Limit == 0 {
+		jw.Limit = 100
+	}
+
+	jw.processor = processor
+	jw.key = "journal:" + strings.Join(jw.Units, ",")
+
+	return nil
+}
+
+func (jw *JournalWatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	jw.cancel = cancel
+	jw.done = make(chan struct{})
+	go jw.watch(ctx)
+}
+
+func (jw *JournalWatcher) Stop() {
+	if jw.cancel != nil {
+		jw.cancel()
+		<-jw.done
+	}
+}
+
+func (jw *JournalWatcher) args() []string {
+	args := []string{"--output=export", "--follow", "--no-pager"}
+
+	if cursor := offsets.GetCursor(jw.key); cursor != "" {
+		args = append(args, "--after-cursor="+cursor)
+	} else {
+		args = append(args, "--lines="+strconv.Itoa(jw.Limit))
+	}
+
+	for _, unit := range jw.Units {
+		args = append(args, "--unit="+unit)
+	}
+
+	return args
+}
+
+func (jw *JournalWatcher) watch(ctx context.Context) {
+	defer close(jw.done)
+
+	for {
+		if err := jw.run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("failed to read journal: %v", err)
+		}
+
+		select {
+		case <-time.After(restartDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (jw *JournalWatcher) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// journalctl keeps running, collect only the stderr tail for the error
+	stderr := tailbuf.NewTailBuffer(stderrTailSize)
+
+	cmd := exec.CommandContext(ctx, journalctl, jw.args()...)
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start journalctl: %w", err)
+	}
+
+	readErr := jw.read(stdout)
+	if readErr != nil {
+		// Stream is broken, restart journalctl from the last cursor
+		cancel()
+	}
+
+	if err := cmd.Wait(); err != nil && readErr == nil {
+		text := strings.TrimSpace(stderr.String())
+		if text != "" {
+			return fmt.Errorf("journalctl: %w: %s", err, text)
+		}
+		return fmt.Errorf("journalctl: %w", err)
+	}
+
+	return readErr
+}
+
+// read processes entries from the journal export stream.
+func (jw *JournalWatcher) read(r io.Reader) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	for {
+		entry, err := readEntry(reader)
+		if err != nil {
+			// Partial entry is dropped without the cursor update,
+			// it is read again after restart from the last cursor.
+			if err == io.EOF && len(entry) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		jw.process(entry)
+	}
+}
+
+func (jw *JournalWatcher) process(entry map[string]string) {
+	cursor := entry["__CURSOR"]
+	mapFields(entry)
+
+	data, err := jw.processor.Serialize(entry)
+	if err != nil {
+		// Realtime timestamp in microseconds is the position of the entry
+		position, _ := strconv.ParseInt(entry["__REALTIME_TIMESTAMP"], 10, 64)
+		line := fmt.Sprintf("%s: %s", entry["unit"], entry["message"])
+		jw.processor.Reject("journal", position, line, err)
+	}
+
+	if data != nil {
+		jw.processor.Write(data)
+	}
+
+	if cursor != "" {
+		offsets.SetCursor(jw.key, cursor)
+	}
+}
+
+// mapFields adds aliases for the well-known journal fields.
+func mapFields(entry map[string]string) {
+	if val, ok := entry["__REALTIME_TIMESTAMP"]; ok {
+		if usec, err := strconv.ParseInt(val, 10, 64); err == nil {
+			entry["time"] = strconv.FormatInt(usec/1000, 10)
+		}
+	}
+
+	if val, ok := entry["_SYSTEMD_UNIT"]; ok {
+		entry["unit"] = val
+	} else if val, ok := entry["UNIT"]; ok {
+		// Messages from systemd about the unit
+		entry["unit"] = val
+	}
+
+	if val, ok := entry["PRIORITY"]; ok {
+		entry["priority"] = val
+		if level, err := strconv.Atoi(val); err == nil && level >= 0 && level < len(levelNames) {
+			entry["level"] = levelNames[level]
+		}
+	}
+
+	for name, alias := range fieldAliases {
+		if val, ok := entry[name]; ok {
+			entry[alias] = val
+		}
+	}
+}
+
+// readEntry reads the single entry in the journal export format.
+// Entries are separated by the empty line. Text fields are "KEY=value",
+// binary fields are "KEY\n" followed by the little-endian 64-bit size, data, and "\n".
+func readEntry(reader *bufio.Reader) (map[string]string, error) {
+	entry := make(map[string]string)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return entry, err
+		}
+
+		line = line[:len(line)-1]
+		if line == "" {
+			if len(entry) == 0 {
+				// Skip extra separators
+				continue
+			}
+			return entry, nil
+		}
+
+		if key, value, ok := strings.Cut(line, "="); ok {
+			entry[key] = value
+			continue
+		}
+
+		// Binary field
+		var size uint64
+		if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+			return entry, fmt.Errorf("read field size (%s): %w", line, err)
+		}
+
+		if size > maxFieldSize {
+			return entry, fmt.Errorf("field is too large (%s): %d bytes", line, size)
+		}
+
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return entry, fmt.Errorf("read field data (%s): %w", line, err)
+		}
+
+		entry[line] = string(data[:size])
+	}
+}
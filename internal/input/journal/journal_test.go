@@ -0,0 +1,236 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fugo-app/fugo/internal/offsets"
+)
+
+type mockProcessor struct {
+	mu        sync.Mutex
+	processed []map[string]any
+	rejected  []string
+	offsets   []int64
+	err       error // Error returned by Serialize
+}
+
+func (p *mockProcessor) Serialize(data map[string]string) (map[string]any, error) {
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result, p.err
+}
+
+func (p *mockProcessor) Write(data map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed = append(p.processed, data)
+}
+
+func (p *mockProcessor) Reject(source string, offset int64, line string, reason error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejected = append(p.rejected, line)
+	p.offsets = append(p.offsets, offset)
+}
+
+// binaryField encodes the field in the journal export binary format.
+func binaryField(key string, value string) string {
+	var buf bytes.Buffer
+	buf.WriteString(key + "\n")
+	binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value + "\n")
+	return buf.String()
+}
+
+func testJournal_Export() string {
+	return "__CURSOR=s=abc;i=1\n" +
+		"__REALTIME_TIMESTAMP=1672574400123456\n" +
+		"_SYSTEMD_UNIT=nginx.service\n" +
+		"PRIORITY=6\n" +
+		"_PID=1234\n" +
+		"_HOSTNAME=web-1\n" +
+		"SYSLOG_IDENTIFIER=nginx\n" +
+		"MESSAGE=server started\n" +
+		"\n" +
+		"__CURSOR=s=abc;i=2\n" +
+		"__REALTIME_TIMESTAMP=1672574401000000\n" +
+		"UNIT=nginx.service\n" +
+		"PRIORITY=3\n" +
+		binaryField("MESSAGE", "line 1\nline 2") +
+		"\n"
+}
+
+func testJournal_Setup(t *testing.T) {
+	require.NoError(t, offsets.Open(filepath.Join(t.TempDir(), "offsets.yaml")), "failed to open offsets")
+	t.Cleanup(offsets.Close)
+}
+
+func TestReadEntry(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(testJournal_Export()))
+
+	entry, err := readEntry(reader)
+	require.NoError(t, err)
+	require.Equal(t, "server started", entry["MESSAGE"])
+	require.Equal(t, "nginx.service", entry["_SYSTEMD_UNIT"])
+
+	entry, err = readEntry(reader)
+	require.NoError(t, err)
+	require.Equal(t, "line 1\nline 2", entry["MESSAGE"], "binary field should be decoded")
+	require.Equal(t, "3", entry["PRIORITY"])
+
+	entry, err = readEntry(reader)
+	require.ErrorIs(t, err, io.EOF)
+	require.Empty(t, entry)
+
+	// Truncated binary field
+	reader = bufio.NewReader(strings.NewReader("MESSAGE\n\x05\x00"))
+	_, err = readEntry(reader)
+	require.Error(t, err)
+}
+
+func TestJournalWatcher_read(t *testing.T) {
+	testJournal_Setup(t)
+
+	processor := &mockProcessor{}
+	watcher := &JournalWatcher{Units: []string{"nginx.service"}}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+
+	require.NoError(t, watcher.read(strings.NewReader(testJournal_Export())))
+	require.Len(t, processor.processed, 2, "entries should be processed")
+	require.Empty(t, processor.rejected)
+
+	first := processor.processed[0]
+	require.Equal(t, "1672574400123", first["time"])
+	require.Equal(t, "nginx.service", first["unit"])
+	require.Equal(t, "6", first["priority"])
+	require.Equal(t, "info", first["level"])
+	require.Equal(t, "server started", first["message"])
+	require.Equal(t, "1234", first["pid"])
+	require.Equal(t, "web-1", first["hostname"])
+	require.Equal(t, "nginx", first["identifier"])
+	require.Equal(t, "nginx.service", first["_SYSTEMD_UNIT"], "original fields should be kept")
+
+	second := processor.processed[1]
+	require.Equal(t, "nginx.service", second["unit"])
+	require.Equal(t, "err", second["level"])
+	require.Equal(t, "line 1\nline 2", second["message"])
+
+	require.Equal(t, "s=abc;i=2", offsets.GetCursor(watcher.key), "cursor should be saved")
+}
+
+func TestJournalWatcher_readRejected(t *testing.T) {
+	testJournal_Setup(t)
+
+	processor := &mockProcessor{err: errors.New("invalid field")}
+	watcher := &JournalWatcher{Units: []string{"nginx.service"}}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+
+	require.NoError(t, watcher.read(strings.NewReader(testJournal_Export())))
+	require.Equal(t, []string{"nginx.service: server started", "nginx.service: line 1\nline 2"}, processor.rejected)
+	require.Equal(t, []int64{1672574400123456, 1672574401000000}, processor.offsets, "entries should be rejected with their position")
+}
+
+func TestJournalWatcher_readTruncated(t *testing.T) {
+	testJournal_Setup(t)
+
+	processor := &mockProcessor{}
+	watcher := &JournalWatcher{Units: []string{"nginx.service"}}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+	offsets.SetCursor(watcher.key, "s=abc;i=0")
+
+	export := testJournal_Export()
+	first := strings.Index(export, "\n\n") + 2
+
+	tests := []struct {
+		name   string
+		stream string
+	}{
+		{"missing separator", export[first : len(export)-1]},
+		{"truncated text field", export[first : first+40]},
+		{"truncated binary field", export[first : len(export)-10]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, watcher.read(strings.NewReader(tt.stream)))
+			require.Empty(t, processor.processed, "partial entry should not be written")
+			require.Equal(t, "s=abc;i=0", offsets.GetCursor(watcher.key), "cursor should not be moved")
+		})
+	}
+
+	// Complete entries before the partial one are processed
+	require.Error(t, watcher.read(strings.NewReader(export[:first+40])))
+	require.Len(t, processor.processed, 1)
+	require.Equal(t, "s=abc;i=1", offsets.GetCursor(watcher.key))
+}
+
+func TestJournalWatcher_args(t *testing.T) {
+	testJournal_Setup(t)
+
+	watcher := &JournalWatcher{Units: []string{"nginx.service", "postgresql@*.service"}, Limit: 10}
+	require.NoError(t, watcher.Init(&mockProcessor{}))
+
+	require.Equal(t, []string{
+		"--output=export",
+		"--follow",
+		"--no-pager",
+		"--lines=10",
+		"--unit=nginx.service",
+		"--unit=postgresql@*.service",
+	}, watcher.args())
+
+	offsets.SetCursor(watcher.key, "s=abc;i=2")
+
+	require.Equal(t, []string{
+		"--output=export",
+		"--follow",
+		"--no-pager",
+		"--after-cursor=s=abc;i=2",
+		"--unit=nginx.service",
+		"--unit=postgresql@*.service",
+	}, watcher.args())
+}
+
+func TestJournalWatcher_runStderr(t *testing.T) {
+	testJournal_Setup(t)
+
+	script := filepath.Join(t.TempDir(), "journalctl")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nyes x | head -c 100000 >&2\necho failed >&2\nexit 1\n"), 0o755))
+
+	stdJournalctl := journalctl
+	journalctl = script
+	t.Cleanup(func() { journalctl = stdJournalctl })
+
+	watcher := &JournalWatcher{}
+	require.NoError(t, watcher.Init(&mockProcessor{}))
+
+	err := watcher.run(context.Background())
+	require.ErrorContains(t, err, "exit status 1")
+	require.True(t, strings.HasSuffix(err.Error(), "failed"), "Stderr tail should be kept")
+	require.Less(t, len(err.Error()), stderrTailSize+100, "Stderr should be limited")
+}
+
+func TestJournalWatcher_Init(t *testing.T) {
+	processor := &mockProcessor{}
+
+	require.Error(t, (&JournalWatcher{Units: []string{""}}).Init(processor), "Unit name is required")
+	require.Error(t, (&JournalWatcher{Limit: -1}).Init(processor), "Invalid limit")
+
+	watcher := &JournalWatcher{}
+	require.NoError(t, watcher.Init(processor))
+	require.Equal(t, 100, watcher.Limit)
+}
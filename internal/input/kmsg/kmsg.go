@@ -0,0 +1,389 @@
+package kmsg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/fugo-app/fugo/internal/input"
+	"github.com/fugo-app/fugo/internal/offsets"
+)
+
+// KmsgWatcher is an implementation of the kernel log input.
+// It reads records from the kernel ring buffer via /dev/kmsg
+// and resumes from the last processed sequence number after restart.
+//
+// Each record provides the following fields:
+// "time" (unix milliseconds), "monotonic" (microseconds since boot), "seq",
+// "priority" (0-7), "level" (e.g. "err"), "facility" (e.g. "kern"), "message",
+// "caller" if the kernel reports it,
+// and lowercased device metadata from the continuation lines, e.g. "subsystem", "device".
+type KmsgWatcher struct {
+	// Path to the kernel log device.
+	// Default: "/dev/kmsg"
+	Path string `yaml:"path,omitempty"`
+
+	processor input.Processor
+
+	key  string // Key to store the last sequence number
+	boot string // Boot ID of the sequence number
+	seq  int64  // Last processed sequence number
+	ok   bool   // Sequence number is defined
+
+	mutex sync.Mutex
+	file  *os.File
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+const defaultPath = "/dev/kmsg"
+
+var levelNames = []string{
+	"emerg",
+	"alert",
+	"crit",
+	"err",
+	"warning",
+	"notice",
+	"info",
+	"debug",
+}
+
+var facilityNames = map[int64]string{
+	0:  "kern",
+	1:  "user",
+	2:  "mail",
+	3:  "daemon",
+	4:  "auth",
+	5:  "syslog",
+	6:  "lpr",
+	7:  "news",
+	8:  "uucp",
+	9:  "cron",
+	10: "authpriv",
+	11: "ftp",
+	16: "local0",
+	17: "local1",
+	18: "local2",
+	19: "local3",
+	20: "local4",
+	21: "local5",
+	22: "local6",
+	23: "local7",
+}
+
+// getBootTime returns the wall clock time of the system boot.
+// The kmsg timestamp is based on the monotonic clock.
+var getBootTime = func() time.Time {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return time.Time{}
+	}
+
+	return time.Now().Add(-time.Duration(ts.Nano()))
+}
+
+// getBootID returns the unique identifier of the current boot.
+// Sequence numbers start from zero on each boot.
+var getBootID = func() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func (kw *KmsgWatcher) Init(processor input.Processor) error {
+	if kw.Path == "" {
+		kw.Path = defaultPath
+	}
+
+	kw.processor = processor
+	kw.key = "kmsg:" + kw.Path
+	kw.boot = getBootID()
+	kw.seq, kw.ok = kw.position()
+
+	return nil
+}
+
+// position returns the saved sequence number if it belongs to the current boot.
+// The position is stored as "<boot id>:<seq>".
+func (kw *KmsgWatcher) position() (int64, bool) {
+	cursor := offsets.GetCursor(kw.key)
+
+	idx := strings.LastIndexByte(cursor, ':')
+	if idx == -1 || cursor[:idx] != kw.boot {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseInt(cursor[idx+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return seq, true
+}
+
+func (kw *KmsgWatcher) Start() {
+	kw.stop = make(chan struct{})
+	kw.done = make(chan struct{})
+	go kw.watch()
+}
+
+func (kw *KmsgWatcher) Stop() {
+	if kw.stop == nil {
+		return
+	}
+
+	close(kw.stop)
+
+	// Unblock the pending read
+	kw.mutex.Lock()
+	if kw.file != nil {
+		kw.file.Close()
+	}
+	kw.mutex.Unlock()
+
+	<-kw.done
+}
+
+func (kw *KmsgWatcher) watch() {
+	defer close(kw.done)
+
+	// Non-blocking mode allows to interrupt the read on stop
+	f, err := os.OpenFile(kw.Path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		log.Printf("failed to open kernel log (%s): %v", kw.Path, err)
+		return
+	}
+
+	kw.mutex.Lock()
+	select {
+	case <-kw.stop:
+		kw.mutex.Unlock()
+		f.Close()
+		return
+	default:
+		kw.file = f
+	}
+	kw.mutex.Unlock()
+
+	if err := kw.read(f); err != nil {
+		log.Printf("failed to read kernel log (%s): %v", kw.Path, err)
+	}
+}
+
+// read processes records until the reader fails or the watcher is stopped.
+func (kw *KmsgWatcher) read(r io.Reader) error {
+	// Each read from /dev/kmsg returns a single record.
+	// Regular files (e.g. for testing) may return several records at once.
+	buffer := make([]byte, 64*1024)
+	var pending []byte
+
+	for {
+		n, err := r.Read(buffer)
+		if n > 0 {
+			pending = append(pending, buffer[:n]...)
+			pending = kw.process(pending)
+		}
+
+		if err == nil {
+			continue
+		}
+
+		select {
+		case <-kw.stop:
+			return nil
+		default:
+		}
+
+		if errors.Is(err, syscall.EPIPE) {
+			// Ring buffer has been overwritten before we read it.
+			// Next read returns the oldest available record.
+			log.Printf("kernel log (%s): records have been overwritten", kw.Path)
+			pending = nil
+			continue
+		}
+
+		if err == io.EOF {
+			select {
+			case <-kw.stop:
+				return nil
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		return err
+	}
+}
+
+// process parses all complete records and returns the remaining data.
+func (kw *KmsgWatcher) process(data []byte) []byte {
+	bootTime := getBootTime()
+
+	var record map[string]string
+	var header string
+
+	emit := func() {
+		if record != nil {
+			kw.emit(header, record)
+			record = nil
+		}
+	}
+
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx == -1 {
+			break
+		}
+
+		line := string(data[:idx])
+		data = data[idx+1:]
+
+		if strings.HasPrefix(line, " ") {
+			// Continuation line with device metadata
+			if record != nil {
+				key, value, ok := strings.Cut(line[1:], "=")
+				if ok && key != "" {
+					record[strings.ToLower(key)] = value
+				}
+			}
+			continue
+		}
+
+		emit()
+
+		if line == "" {
+			continue
+		}
+
+		if r, err := parseRecord(line, bootTime); err != nil {
+			kw.processor.Reject(kw.Path, kw.seq, line, err)
+		} else {
+			header = line
+			record = r
+		}
+	}
+
+	emit()
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return data
+}
+
+func (kw *KmsgWatcher) emit(header string, record map[string]string) {
+	seq, _ := strconv.ParseInt(record["seq"], 10, 64)
+	if kw.ok && seq <= kw.seq {
+		// Already processed
+		return
+	}
+
+	if kw.ok && seq > kw.seq+1 {
+		log.Printf("kernel log (%s): %d records lost", kw.Path, seq-kw.seq-1)
+	}
+
+	kw.seq = seq
+	kw.ok = true
+
+	data, err := kw.processor.Serialize(record)
+	if err != nil {
+		kw.processor.Reject(kw.Path, seq, header, err)
+	}
+
+	if data != nil {
+		kw.processor.Write(data)
+	}
+
+	offsets.SetCursor(kw.key, kw.boot+":"+strconv.FormatInt(seq, 10))
+}
+
+// parseRecord parses the record header: "priority,seq,timestamp,flags[,...];message"
+func parseRecord(line string, bootTime time.Time) (map[string]string, error) {
+	prefix, message, ok := strings.Cut(line, ";")
+	if !ok {
+		return nil, fmt.Errorf("invalid record: missing message")
+	}
+
+	parts := strings.Split(prefix, ",")
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("invalid record prefix: %s", prefix)
+	}
+
+	priority, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid priority: %w", err)
+	}
+
+	if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid sequence number: %w", err)
+	}
+
+	monotonic, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	level := priority & 7
+	facility := priority >> 3
+
+	record := map[string]string{
+		"time":      strconv.FormatInt(bootTime.Add(time.Duration(monotonic)*time.Microsecond).UnixMilli(), 10),
+		"monotonic": parts[2],
+		"seq":       parts[1],
+		"priority":  strconv.FormatInt(level, 10),
+		"level":     levelNames[level],
+		"facility":  strconv.FormatInt(facility, 10),
+		"message":   unescape(message),
+	}
+
+	if name, ok := facilityNames[facility]; ok {
+		record["facility"] = name
+	}
+
+	// Optional fields, e.g. "caller=T123"
+	for _, part := range parts[4:] {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			record[key] = value
+		}
+	}
+
+	return record, nil
+}
+
+// unescape decodes non-printable characters escaped by the kernel as "\xHH".
+func unescape(s string) string {
+	if !strings.Contains(s, `\x`) {
+		return s
+	}
+
+	var out strings.Builder
+	out.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if b, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				out.WriteByte(byte(b))
+				i += 3
+				continue
+			}
+		}
+		out.WriteByte(s[i])
+	}
+
+	return out.String()
+}
@@ -0,0 +1,209 @@
+package kmsg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fugo-app/fugo/internal/offsets"
+)
+
+type mockProcessor struct {
+	mu        sync.Mutex
+	processed []map[string]any
+	rejected  []string
+}
+
+func (p *mockProcessor) Serialize(data map[string]string) (map[string]any, error) {
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result, nil
+}
+
+func (p *mockProcessor) Write(data map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.processed = append(p.processed, data)
+}
+
+func (p *mockProcessor) Reject(source string, offset int64, line string, reason error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rejected = append(p.rejected, line)
+}
+
+func (p *mockProcessor) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.processed)
+}
+
+const testRecords = "6,1,5000000,-;Linux version 6.1.0\n" +
+	"3,2,5100000,-,caller=T1;ata1: failed command: \\x1b[1mREAD\n" +
+	" SUBSYSTEM=scsi\n" +
+	" DEVICE=+scsi:0:0:0:0\n" +
+	"broken record\n" +
+	"11,3,5200000,-;oom-kill: task=java pid=1234\n"
+
+func testKmsg_Setup(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	require.NoError(t, offsets.Open(filepath.Join(tempDir, "offsets.yaml")), "failed to open offsets")
+	t.Cleanup(offsets.Close)
+
+	stdBootTime, stdBootID := getBootTime, getBootID
+	t.Cleanup(func() {
+		getBootTime, getBootID = stdBootTime, stdBootID
+	})
+
+	bootTime := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	getBootTime = func() time.Time { return bootTime }
+	getBootID = func() string { return "test-boot" }
+
+	path := filepath.Join(tempDir, "kmsg")
+	require.NoError(t, os.WriteFile(path, []byte(testRecords), 0644))
+
+	return path
+}
+
+func TestKmsgWatcher(t *testing.T) {
+	path := testKmsg_Setup(t)
+
+	processor := &mockProcessor{}
+	watcher := &KmsgWatcher{Path: path}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+
+	watcher.Start()
+	require.Eventually(t, func() bool {
+		return processor.count() == 3
+	}, 2*time.Second, 50*time.Millisecond, "records should be processed")
+	watcher.Stop()
+
+	expected := []map[string]any{
+		{
+			"time":      "1672574405000",
+			"monotonic": "5000000",
+			"seq":       "1",
+			"priority":  "6",
+			"level":     "info",
+			"facility":  "kern",
+			"message":   "Linux version 6.1.0",
+		},
+		{
+			"time":      "1672574405100",
+			"monotonic": "5100000",
+			"seq":       "2",
+			"priority":  "3",
+			"level":     "err",
+			"facility":  "kern",
+			"message":   "ata1: failed command: \x1b[1mREAD",
+			"caller":    "T1",
+			"subsystem": "scsi",
+			"device":    "+scsi:0:0:0:0",
+		},
+		{
+			"time":      "1672574405200",
+			"monotonic": "5200000",
+			"seq":       "3",
+			"priority":  "3",
+			"level":     "err",
+			"facility":  "user",
+			"message":   "oom-kill: task=java pid=1234",
+		},
+	}
+	require.Equal(t, expected, processor.processed, "processed records don't match")
+	require.Equal(t, []string{"broken record"}, processor.rejected, "rejected records don't match")
+
+	require.Equal(t, "kmsg:"+path, watcher.key)
+	require.Equal(t, "test-boot:3", offsets.GetCursor(watcher.key), "sequence number should be saved")
+
+	// Resume from the last sequence number
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("4,4,5300000,-;new record\n")
+	require.NoError(t, err)
+	f.Close()
+
+	processor = &mockProcessor{}
+	watcher = &KmsgWatcher{Path: path}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+
+	watcher.Start()
+	require.Eventually(t, func() bool {
+		return processor.count() == 1
+	}, 2*time.Second, 50*time.Millisecond, "new record should be processed")
+	watcher.Stop()
+
+	require.Equal(t, "new record", processor.processed[0]["message"])
+	require.Equal(t, "warning", processor.processed[0]["level"])
+
+	// Sequence numbers restart after reboot, the same key is reused
+	getBootID = func() string { return "next-boot" }
+
+	processor = &mockProcessor{}
+	watcher = &KmsgWatcher{Path: path}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+	require.False(t, watcher.ok, "sequence number of the previous boot should be ignored")
+
+	watcher.Start()
+	require.Eventually(t, func() bool {
+		return processor.count() == 4
+	}, 2*time.Second, 50*time.Millisecond, "all records should be processed")
+	watcher.Stop()
+
+	require.Equal(t, "next-boot:4", offsets.GetCursor(watcher.key))
+}
+
+// overrunReader returns EPIPE once between the records, like /dev/kmsg on ring buffer overrun.
+type overrunReader struct {
+	records []string
+	index   int
+}
+
+func (r *overrunReader) Read(p []byte) (int, error) {
+	if r.index >= len(r.records) {
+		return 0, os.ErrClosed
+	}
+
+	record := r.records[r.index]
+	r.index++
+
+	if record == "" {
+		return 0, &os.PathError{Op: "read", Path: "/dev/kmsg", Err: syscall.EPIPE}
+	}
+
+	return copy(p, record), nil
+}
+
+func TestKmsgWatcher_Overrun(t *testing.T) {
+	testKmsg_Setup(t)
+
+	processor := &mockProcessor{}
+	watcher := &KmsgWatcher{Path: "/dev/kmsg"}
+	require.NoError(t, watcher.Init(processor), "failed to init watcher")
+	watcher.stop = make(chan struct{})
+
+	reader := &overrunReader{
+		records: []string{
+			"6,10,1000,-;first\n",
+			"",
+			"6,25,2000,-;after overrun\n",
+		},
+	}
+
+	err := watcher.read(reader)
+	require.ErrorIs(t, err, os.ErrClosed)
+	require.NotErrorIs(t, err, io.EOF)
+
+	require.Len(t, processor.processed, 2, "records around the overrun should be processed")
+	require.Equal(t, "after overrun", processor.processed[1]["message"])
+	require.Equal(t, int64(25), watcher.seq)
+}
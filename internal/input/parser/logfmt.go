@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// logfmtParser extracts fields from a logfmt line: key=value key2="quoted value" flag
+type logfmtParser struct{}
+
+func newLogfmtParser() *logfmtParser {
+	return &logfmtParser{}
+}
+
+func (l *logfmtParser) Parse(line string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	i := 0
+	for i < len(line) {
+		// Skip spaces
+		for i < len(line) && line[i] <= ' ' {
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+
+		// Key
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' {
+			i++
+		}
+		key := line[start:i]
+
+		if i >= len(line) || line[i] != '=' {
+			// Key without value
+			if key != "" {
+				result[key] = ""
+			}
+			continue
+		}
+		i++ // skip '='
+
+		// Value
+		var value string
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, ErrNoMatch
+			}
+
+			quoted := line[i : end+1]
+			if v, err := strconv.Unquote(quoted); err == nil {
+				value = v
+			} else {
+				value = strings.Trim(quoted, `"`)
+			}
+			i = end + 1
+		} else {
+			start := i
+			for i < len(line) && line[i] > ' ' {
+				i++
+			}
+			value = line[start:i]
+		}
+
+		if key != "" {
+			result[key] = value
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtParser_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "parse logfmt line",
+			line: `time=2023-01-01T12:00:00Z level=info msg="Test message" duration=12ms`,
+			want: map[string]string{
+				"time":     "2023-01-01T12:00:00Z",
+				"level":    "info",
+				"msg":      "Test message",
+				"duration": "12ms",
+			},
+			wantErr: false,
+		},
+		{
+			name: "escaped quotes and empty values",
+			line: `msg="say \"hello\"" empty= flag`,
+			want: map[string]string{
+				"msg":   `say "hello"`,
+				"empty": "",
+				"flag":  "",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unterminated quote",
+			line:    `msg="broken`,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "empty line",
+			line:    `   `,
+			want:    nil,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newLogfmtParser()
+			got, err := parser.Parse(tt.line)
+			if tt.wantErr {
+				require.Error(t, err, "Expected error but got none")
+			} else {
+				require.NoError(t, err, "Unexpected error")
+				require.Equal(t, tt.want, got, "Map not equal", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoMatch is returned when the line does not match the configured format.
+var ErrNoMatch = errors.New("line does not match the format")
+
+// Parser extracts fields from the single log line.
+type Parser interface {
+	Parse(line string) (map[string]string, error)
+}
+
+// New returns the parser for the log format: "plain" (default), "json", or "logfmt".
+// Regex is required for the plain format.
+func New(format string, regex string) (Parser, error) {
+	format = strings.ToLower(format)
+	if format == "" {
+		format = "plain"
+	}
+
+	switch format {
+	case "plain":
+		if regex == "" {
+			return nil, fmt.Errorf("regex is required for plain format")
+		}
+
+		p, err := newPlainParser(regex)
+		if err != nil {
+			return nil, fmt.Errorf("plain parser: %w", err)
+		}
+		return p, nil
+	case "json":
+		p, err := newJsonParser()
+		if err != nil {
+			return nil, fmt.Errorf("json parser: %w", err)
+		}
+		return p, nil
+	case "logfmt":
+		return newLogfmtParser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
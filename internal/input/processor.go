@@ -1,9 +1,16 @@
 package input
 
 type Processor interface {
-	// Serialize converts raw data to a structured data
-	Serialize(data map[string]string) map[string]any
+	// Serialize converts raw data to a structured data.
+	// Returns error if some fields could not be converted,
+	// in that case structured data is still returned with default values.
+	// Returns nil if the record is dropped by the agent rules.
+	Serialize(data map[string]string) (map[string]any, error)
 
 	// Write writes structured data to the storage
 	Write(data map[string]any)
+
+	// Reject reports the raw line that could not be parsed or converted.
+	// Source is the origin of the line (e.g. file path) and offset is the line position in the source.
+	Reject(source string, offset int64, line string, reason error)
 }
@@ -0,0 +1,379 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+var cgroupFields = []*field.Field{
+	{
+		Name:        "cgroup_path",
+		Type:        "string",
+		Description: "Cgroup path relative to the root",
+	},
+	{
+		Name:        "cgroup_cpu_usage",
+		Type:        "float",
+		Description: "CPU usage percentage of a single core",
+	},
+	{
+		Name:        "cgroup_cpu_throttled",
+		Type:        "int",
+		Description: "Delta of throttled periods",
+	},
+	{
+		Name:        "cgroup_cpu_throttled_time",
+		Type:        "int",
+		Description: "Delta of throttled time in microseconds",
+	},
+	{
+		Name:        "cgroup_mem_current",
+		Type:        "int",
+		Description: "Memory usage in bytes",
+	},
+	{
+		Name:        "cgroup_mem_max",
+		Type:        "int",
+		Description: "Memory limit in bytes, 0 if unlimited",
+	},
+	{
+		Name:        "cgroup_mem_ratio",
+		Type:        "float",
+		Description: "Memory usage percentage of the limit, 0 if unlimited",
+	},
+	{
+		Name:        "cgroup_io_read_bytes",
+		Type:        "int",
+		Description: "Delta of read bytes",
+	},
+	{
+		Name:        "cgroup_io_write_bytes",
+		Type:        "int",
+		Description: "Delta of written bytes",
+	},
+	{
+		Name:        "cgroup_pids",
+		Type:        "int",
+		Description: "Number of processes",
+	},
+}
+
+const defaultCgroupRoot = "/sys/fs/cgroup"
+
+type cgroupInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Root of the cgroup v2 hierarchy.
+	// Default: "/sys/fs/cgroup"
+	Root string `yaml:"root,omitempty"`
+
+	// Glob patterns of cgroups relative to the root.
+	// The "**" segment matches any number of nested cgroups, including none.
+	// Example: ["system.slice/*.service", "kubepods.slice/**/*.scope"]
+	Paths []string `yaml:"paths"`
+
+	state map[string]*cgroupState
+}
+
+// cgroupState keeps the previous counters of the cgroup.
+type cgroupState struct {
+	time          time.Time
+	usageUsec     uint64
+	throttled     uint64
+	throttledUsec uint64
+	readBytes     uint64
+	writeBytes    uint64
+}
+
+func (ci *cgroupInfo) fields() []*field.Field {
+	return cgroupFields
+}
+
+func (ci *cgroupInfo) init() error {
+	if ci.Root == "" {
+		ci.Root = defaultCgroupRoot
+	}
+
+	if len(ci.Paths) == 0 {
+		return fmt.Errorf("paths is required")
+	}
+
+	for _, pattern := range ci.Paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid cgroup pattern (%s): %w", pattern, err)
+		}
+	}
+
+	ci.state = make(map[string]*cgroupState)
+
+	return nil
+}
+
+// cgroups returns the list of cgroups matching the patterns.
+func (ci *cgroupInfo) cgroups() []string {
+	found := make(map[string]struct{})
+
+	for _, pattern := range ci.Paths {
+		var matches []string
+		if strings.Contains(pattern, "**") {
+			matches = ci.walk(pattern)
+		} else {
+			matches, _ = filepath.Glob(filepath.Join(ci.Root, pattern))
+		}
+
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				continue
+			}
+
+			if rel, err := filepath.Rel(ci.Root, path); err == nil {
+				found[rel] = struct{}{}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(found))
+	for path := range found {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// walk returns the directories matching the pattern with "**" segments.
+// The tree is walked from the longest prefix without glob characters.
+func (ci *cgroupInfo) walk(pattern string) []string {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+
+	prefix := 0
+	for prefix < len(segments) && !strings.ContainsAny(segments[prefix], "*?[\\") {
+		prefix++
+	}
+
+	base := filepath.Join(ci.Root, filepath.Join(segments[:prefix]...))
+	segments = segments[prefix:]
+
+	var result []string
+
+	filepath.WalkDir(base, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return nil
+		}
+
+		var parts []string
+		if rel != "." {
+			parts = strings.Split(filepath.ToSlash(rel), "/")
+		}
+
+		if matchSegments(segments, parts) {
+			result = append(result, path)
+		}
+
+		return nil
+	})
+
+	return result
+}
+
+// matchSegments checks if the path segments match the pattern segments.
+// The "**" segment matches zero or more path segments.
+func matchSegments(pattern []string, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		path = path[1:]
+	}
+
+	return len(path) == 0
+}
+
+// collect returns a row per matched cgroup.
+func (ci *cgroupInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if ci == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	paths := ci.cgroups()
+
+	rows := make([]map[string]any, 0, len(paths))
+	state := make(map[string]*cgroupState, len(paths))
+
+	for _, path := range paths {
+		row := map[string]any{
+			"time": data["time"],
+		}
+
+		current, err := ci.collectCgroup(path, now, row)
+		if err != nil {
+			log.Printf("Error on collecting cgroup status (%s): %v\n", path, err)
+			continue
+		}
+
+		state[path] = current
+		rows = append(rows, row)
+	}
+
+	ci.state = state
+
+	return rows, nil
+}
+
+func (ci *cgroupInfo) collectCgroup(path string, now time.Time, data map[string]any) (*cgroupState, error) {
+	dir := filepath.Join(ci.Root, path)
+
+	cpuStat, err := readKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, fmt.Errorf("read cpu.stat: %w", err)
+	}
+
+	current := &cgroupState{
+		time:          now,
+		usageUsec:     cpuStat["usage_usec"],
+		throttled:     cpuStat["nr_throttled"],
+		throttledUsec: cpuStat["throttled_usec"],
+	}
+	current.readBytes, current.writeBytes = readIOStat(filepath.Join(dir, "io.stat"))
+
+	data["cgroup_path"] = path
+	data["cgroup_cpu_usage"] = float64(0)
+	data["cgroup_cpu_throttled"] = int64(0)
+	data["cgroup_cpu_throttled_time"] = int64(0)
+	data["cgroup_io_read_bytes"] = int64(0)
+	data["cgroup_io_write_bytes"] = int64(0)
+
+	if prev, ok := ci.state[path]; ok {
+		if elapsed := now.Sub(prev.time).Seconds(); elapsed > 0 {
+			usage := float64(counterDelta(current.usageUsec, prev.usageUsec)) / 1e6 / elapsed * 100
+			data["cgroup_cpu_usage"] = math.Round(usage*100) / 100
+		}
+
+		data["cgroup_cpu_throttled"] = counterDelta(current.throttled, prev.throttled)
+		data["cgroup_cpu_throttled_time"] = counterDelta(current.throttledUsec, prev.throttledUsec)
+		data["cgroup_io_read_bytes"] = counterDelta(current.readBytes, prev.readBytes)
+		data["cgroup_io_write_bytes"] = counterDelta(current.writeBytes, prev.writeBytes)
+	}
+
+	memCurrent, _ := readCgroupValue(filepath.Join(dir, "memory.current"))
+	memMax, _ := readCgroupValue(filepath.Join(dir, "memory.max"))
+
+	data["cgroup_mem_current"] = int64(memCurrent)
+	data["cgroup_mem_max"] = int64(memMax)
+	data["cgroup_mem_ratio"] = percent(float64(memCurrent), float64(memMax))
+
+	pids, _ := readCgroupValue(filepath.Join(dir, "pids.current"))
+	data["cgroup_pids"] = int64(pids)
+
+	return current, nil
+}
+
+// readCgroupValue reads the single value file. The "max" value is returned as 0.
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readKeyValues reads the flat keyed file, e.g. "usage_usec 1000".
+func readKeyValues(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		if val, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = val
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// readIOStat returns read and written bytes summed across all devices.
+// Line format: "8:0 rbytes=1000 wbytes=2000 rios=10 wios=20 dbytes=0 dios=0"
+func readIOStat(path string) (uint64, uint64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+
+	var read, write uint64
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		for _, item := range fields[1:] {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				continue
+			}
+
+			val, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			switch key {
+			case "rbytes":
+				read += val
+			case "wbytes":
+				write += val
+			}
+		}
+	}
+
+	return read, write
+}
@@ -0,0 +1,167 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCgroup_Write(t *testing.T, root string, path string, files map[string]string) {
+	dir := filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestCgroupInfo_collect(t *testing.T) {
+	root := t.TempDir()
+
+	testCgroup_Write(t, root, "system.slice/nginx.service", map[string]string{
+		"cpu.stat":       "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000\nnr_periods 10\nnr_throttled 2\nthrottled_usec 5000\n",
+		"memory.current": "268435456\n",
+		"memory.max":     "1073741824\n",
+		"io.stat":        "8:0 rbytes=1000 wbytes=2000 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=500 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n",
+		"pids.current":   "5\n",
+	})
+	testCgroup_Write(t, root, "system.slice/cron.service", map[string]string{
+		"cpu.stat":       "usage_usec 100\n",
+		"memory.current": "1024\n",
+		"memory.max":     "max\n",
+	})
+	testCgroup_Write(t, root, "system.slice/dev-hugepages.mount", map[string]string{
+		"cpu.stat": "usage_usec 0\n",
+	})
+	// Not a directory
+	require.NoError(t, os.WriteFile(filepath.Join(root, "system.slice", "broken.service"), nil, 0644))
+
+	ci := &cgroupInfo{
+		Root:  root,
+		Paths: []string{"system.slice/*.service"},
+	}
+	require.NoError(t, ci.init())
+
+	data := map[string]any{"time": int64(1)}
+	rows, err := ci.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	cron := rows[0]
+	require.Equal(t, "system.slice/cron.service", cron["cgroup_path"])
+	require.Equal(t, int64(1024), cron["cgroup_mem_current"])
+	require.Equal(t, int64(0), cron["cgroup_mem_max"], "unlimited memory should be reported as 0")
+	require.Equal(t, float64(0), cron["cgroup_mem_ratio"])
+	require.Equal(t, int64(0), cron["cgroup_pids"])
+
+	nginx := rows[1]
+	require.Equal(t, "system.slice/nginx.service", nginx["cgroup_path"])
+	require.Equal(t, int64(1), nginx["time"])
+	require.Equal(t, float64(25), nginx["cgroup_mem_ratio"])
+	require.Equal(t, int64(5), nginx["cgroup_pids"])
+	require.Equal(t, int64(0), nginx["cgroup_cpu_throttled"], "first tick has no deltas")
+
+	// Half of the core in 10 seconds
+	ci.state["system.slice/nginx.service"].time = time.Now().Add(-10 * time.Second)
+	testCgroup_Write(t, root, "system.slice/nginx.service", map[string]string{
+		"cpu.stat": "usage_usec 6000000\nnr_throttled 7\nthrottled_usec 9000\n",
+		"io.stat":  "8:0 rbytes=3000 wbytes=2500 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=500 wbytes=0 rios=1 wios=0 dbytes=0 dios=0\n",
+	})
+
+	rows, err = ci.collect(data)
+	require.NoError(t, err)
+
+	nginx = rows[1]
+	require.InDelta(t, 50, nginx["cgroup_cpu_usage"], 1)
+	require.Equal(t, int64(5), nginx["cgroup_cpu_throttled"])
+	require.Equal(t, int64(4000), nginx["cgroup_cpu_throttled_time"])
+	require.Equal(t, int64(2000), nginx["cgroup_io_read_bytes"])
+	require.Equal(t, int64(500), nginx["cgroup_io_write_bytes"])
+
+	// Removed cgroup is forgotten
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "system.slice", "cron.service")))
+	rows, err = ci.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.NotContains(t, ci.state, "system.slice/cron.service")
+}
+
+func TestCgroupInfo_cgroups(t *testing.T) {
+	root := t.TempDir()
+
+	for _, path := range []string{
+		"kubepods.slice/pod-a/cri-a1.scope",
+		"kubepods.slice/burstable.slice/pod-b/cri-b1.scope",
+		"kubepods.slice/burstable.slice/pod-b/cri-b1.scope/init.scope",
+		"kubepods.slice/burstable.slice/pod-c.slice",
+		"system.slice/docker-1.scope",
+	} {
+		testCgroup_Write(t, root, path, nil)
+	}
+	// Files are not cgroups
+	require.NoError(t, os.WriteFile(filepath.Join(root, "kubepods.slice", "file.scope"), nil, 0644))
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{
+			"kubepods.slice/**/*.scope",
+			[]string{
+				"kubepods.slice/burstable.slice/pod-b/cri-b1.scope",
+				"kubepods.slice/burstable.slice/pod-b/cri-b1.scope/init.scope",
+				"kubepods.slice/pod-a/cri-a1.scope",
+			},
+		},
+		{
+			"kubepods.slice/**/pod-*/*.scope",
+			[]string{
+				"kubepods.slice/burstable.slice/pod-b/cri-b1.scope",
+				"kubepods.slice/pod-a/cri-a1.scope",
+			},
+		},
+		{
+			"**/*.slice",
+			[]string{
+				"kubepods.slice",
+				"kubepods.slice/burstable.slice",
+				"kubepods.slice/burstable.slice/pod-c.slice",
+				"system.slice",
+			},
+		},
+		{
+			"kubepods.slice/**",
+			[]string{
+				"kubepods.slice",
+				"kubepods.slice/burstable.slice",
+				"kubepods.slice/burstable.slice/pod-b",
+				"kubepods.slice/burstable.slice/pod-b/cri-b1.scope",
+				"kubepods.slice/burstable.slice/pod-b/cri-b1.scope/init.scope",
+				"kubepods.slice/burstable.slice/pod-c.slice",
+				"kubepods.slice/pod-a",
+				"kubepods.slice/pod-a/cri-a1.scope",
+			},
+		},
+		{"missing.slice/**/*.scope", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			ci := &cgroupInfo{Root: root, Paths: []string{tt.pattern}}
+			require.NoError(t, ci.init())
+			require.Equal(t, tt.want, ci.cgroups())
+		})
+	}
+}
+
+func TestCgroupInfo_init(t *testing.T) {
+	require.Error(t, (&cgroupInfo{}).init(), "paths is required")
+	require.Error(t, (&cgroupInfo{Paths: []string{"system.slice/["}}).init(), "invalid pattern")
+
+	ci := &cgroupInfo{Paths: []string{"system.slice/*.service"}}
+	require.NoError(t, ci.init())
+	require.Equal(t, defaultCgroupRoot, ci.Root)
+}
@@ -0,0 +1,72 @@
+package system
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/fugo-app/fugo/pkg/duration"
+)
+
+// collector is a source of system metrics.
+type collector interface {
+	// config returns the common collector configuration.
+	config() *collectorConfig
+
+	// init validates the configuration and prepares the collector.
+	init() error
+
+	// fields returns the list of fields reported by the collector.
+	fields() []*field.Field
+
+	// collect adds metrics to the main row and returns additional rows if any.
+	collect(data map[string]any) ([]map[string]any, error)
+}
+
+// collectorConfig is the common configuration of the collector.
+type collectorConfig struct {
+	// Enables or disables the collector.
+	// Default: true for cpu and mem, true for other collectors if they are defined
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// Interval to collect metrics.
+	// Collectors with the same interval are reported in the same row.
+	// Default: interval of the system input
+	Interval string `yaml:"interval,omitempty"`
+
+	name     string
+	interval time.Duration
+}
+
+func (cc *collectorConfig) config() *collectorConfig {
+	return cc
+}
+
+func (cc *collectorConfig) isEnabled() bool {
+	return cc.Enabled == nil || *cc.Enabled
+}
+
+func (cc *collectorConfig) initConfig(name string, interval time.Duration) error {
+	cc.name = name
+	cc.interval = interval
+
+	if cc.Interval != "" {
+		d, err := duration.Parse(cc.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval value: %w", err)
+		}
+		cc.interval = d
+	}
+
+	if cc.interval <= 0 {
+		return fmt.Errorf("interval should be positive")
+	}
+
+	return nil
+}
+
+// collectorGroup is a set of collectors with the same interval.
+type collectorGroup struct {
+	interval   time.Duration
+	collectors []collector
+}
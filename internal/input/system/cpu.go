@@ -1,9 +1,15 @@
 package system
 
 import (
+	"bufio"
 	"fmt"
+	"maps"
 	"math"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fugo-app/fugo/internal/field"
 	"github.com/shirou/gopsutil/v4/cpu"
@@ -38,53 +44,301 @@ var cpuFields = []*field.Field{
 	},
 }
 
+var cpuBreakdownFields = []*field.Field{
+	{
+		Name:        "cpu_user",
+		Type:        "float",
+		Description: "CPU time in user mode percentage",
+	},
+	{
+		Name:        "cpu_system",
+		Type:        "float",
+		Description: "CPU time in kernel mode percentage",
+	},
+	{
+		Name:        "cpu_iowait",
+		Type:        "float",
+		Description: "CPU time waiting for I/O percentage",
+	},
+	{
+		Name:        "cpu_steal",
+		Type:        "float",
+		Description: "CPU time stolen by the hypervisor percentage",
+	},
+	{
+		Name:        "cpu_irq",
+		Type:        "float",
+		Description: "CPU time servicing interrupts percentage",
+	},
+	{
+		Name:        "cpu_softirq",
+		Type:        "float",
+		Description: "CPU time servicing soft interrupts percentage",
+	},
+	{
+		Name:        "cpu_ctxt_rate",
+		Type:        "float",
+		Description: "Context switches per second",
+	},
+	{
+		Name:        "cpu_intr_rate",
+		Type:        "float",
+		Description: "Interrupts per second",
+	},
+}
+
+var cpuCoreField = &field.Field{
+	Name:        "cpu_core",
+	Type:        "int",
+	Description: "CPU core number",
+}
+
+// getCpuTimes returns the aggregate or per-core CPU times.
+var getCpuTimes = cpu.Times
+
+// Path to the kernel statistics.
+var procStatPath = "/proc/stat"
+
 type cpuInfo struct {
-	ok   bool
-	used float64
-	idle float64
+	collectorConfig `yaml:",inline"`
+
+	// Report each CPU core as a separate row with the "cpu_core" field.
+	PerCore bool `yaml:"per_core,omitempty"`
+
+	// Report CPU time breakdown (user, system, iowait, steal, irq, softirq),
+	// context switches and interrupts per second.
+	Breakdown bool `yaml:"breakdown,omitempty"`
+
+	ok    bool
+	total cpuTimes
+	cores []cpuTimes
+	stat  cpuStat
 }
 
-func (ci *cpuInfo) collect(data map[string]any) error {
+// cpuStat keeps the kernel counters used to calculate rates.
+type cpuStat struct {
+	time time.Time
+	ctxt uint64
+	intr uint64
+}
+
+// cpuTimes keeps the CPU time counters used to calculate percentages.
+type cpuTimes struct {
+	used    float64
+	idle    float64
+	user    float64
+	system  float64
+	iowait  float64
+	steal   float64
+	irq     float64
+	softirq float64
+}
+
+func newCpuTimes(t cpu.TimesStat) cpuTimes {
+	return cpuTimes{
+		used: t.User +
+			t.Nice +
+			t.System +
+			t.Irq +
+			t.Softirq +
+			t.Steal +
+			t.Guest +
+			t.GuestNice,
+		idle:    t.Idle + t.Iowait,
+		user:    t.User + t.Nice,
+		system:  t.System,
+		iowait:  t.Iowait,
+		steal:   t.Steal,
+		irq:     t.Irq,
+		softirq: t.Softirq,
+	}
+}
+
+func (ci *cpuInfo) init() error {
+	return nil
+}
+
+func (ci *cpuInfo) fields() []*field.Field {
+	fields := make([]*field.Field, 0)
+	fields = append(fields, cpuFields...)
+
+	if ci.Breakdown {
+		fields = append(fields, cpuBreakdownFields...)
+	}
+
+	if ci.PerCore {
+		fields = append(fields, cpuCoreField)
+	}
+
+	return fields
+}
+
+func percent(delta float64, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return math.Round(delta/total*100*100) / 100
+}
+
+// usage sets the CPU usage fields from the difference between samples.
+func (ci *cpuInfo) usage(prev *cpuTimes, current cpuTimes, data map[string]any) {
+	if prev == nil {
+		prev = &current
+	}
+
+	total := (current.used - prev.used) + (current.idle - prev.idle)
+	data["cpu_usage"] = percent(current.used-prev.used, total)
+
+	if ci.Breakdown {
+		data["cpu_user"] = percent(current.user-prev.user, total)
+		data["cpu_system"] = percent(current.system-prev.system, total)
+		data["cpu_iowait"] = percent(current.iowait-prev.iowait, total)
+		data["cpu_steal"] = percent(current.steal-prev.steal, total)
+		data["cpu_irq"] = percent(current.irq-prev.irq, total)
+		data["cpu_softirq"] = percent(current.softirq-prev.softirq, total)
+	}
+}
+
+// collect adds the CPU metrics to the main row and returns rows per core if enabled.
+// Metrics and saved counters are updated only if the whole collection succeeds.
+func (ci *cpuInfo) collect(data map[string]any) ([]map[string]any, error) {
+	row := make(map[string]any)
+
 	// Load average
 	loadAvg, err := load.Avg()
 	if err != nil {
-		return fmt.Errorf("get load average: %w", err)
+		return nil, fmt.Errorf("get load average: %w", err)
 	}
-	data["la_1"] = math.Round(loadAvg.Load1*100) / 100
-	data["la_5"] = math.Round(loadAvg.Load5*100) / 100
-	data["la_15"] = math.Round(loadAvg.Load15*100) / 100
+	row["la_1"] = math.Round(loadAvg.Load1*100) / 100
+	row["la_5"] = math.Round(loadAvg.Load5*100) / 100
+	row["la_15"] = math.Round(loadAvg.Load15*100) / 100
 
 	// Calculate CPU usage
-	cpuTimes, err := cpu.Times(false)
+	times, err := getCpuTimes(false)
 	if err != nil {
-		return fmt.Errorf("get cpu times: %w", err)
+		return nil, fmt.Errorf("get cpu times: %w", err)
 	}
 
-	cpuTime := cpuTimes[0]
-	cpuUsed := cpuTime.User +
-		cpuTime.Nice +
-		cpuTime.System +
-		cpuTime.Irq +
-		cpuTime.Softirq +
-		cpuTime.Steal +
-		cpuTime.Guest +
-		cpuTime.GuestNice
-	cpuIdle := cpuTime.Idle + cpuTime.Iowait
-
+	total := newCpuTimes(times[0])
 	if ci.ok {
-		deltaUsed := cpuUsed - ci.used
-		deltaIdle := cpuIdle - ci.idle
-		total := deltaUsed + deltaIdle
-		data["cpu_usage"] = math.Round(deltaUsed/total*100*100) / 100
+		ci.usage(&ci.total, total, row)
 	} else {
-		data["cpu_usage"] = float64(0)
-		ci.ok = true
+		ci.usage(nil, total, row)
 	}
 
-	data["cpu_cores"] = int64(runtime.NumCPU())
+	row["cpu_cores"] = int64(runtime.NumCPU())
 
-	ci.used = cpuUsed
-	ci.idle = cpuIdle
+	var stat cpuStat
+	if ci.Breakdown {
+		if stat, err = ci.collectStat(row); err != nil {
+			return nil, err
+		}
+	}
 
-	return nil
+	var rows []map[string]any
+	var cores []cpuTimes
+	if ci.PerCore {
+		rows, cores, err = ci.collectCores(data["time"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maps.Copy(data, row)
+
+	ci.total = total
+	ci.cores = cores
+	ci.stat = stat
+	ci.ok = true
+
+	return rows, nil
+}
+
+// collectCores returns rows per core and the current counters of the cores.
+func (ci *cpuInfo) collectCores(timestamp any) ([]map[string]any, []cpuTimes, error) {
+	coreTimes, err := getCpuTimes(true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get cpu times per core: %w", err)
+	}
+
+	cores := make([]cpuTimes, len(coreTimes))
+	rows := make([]map[string]any, len(coreTimes))
+
+	for i := range coreTimes {
+		cores[i] = newCpuTimes(coreTimes[i])
+
+		row := map[string]any{
+			"time":     timestamp,
+			"cpu_core": int64(i),
+		}
+
+		if ci.ok && i < len(ci.cores) {
+			ci.usage(&ci.cores[i], cores[i], row)
+		} else {
+			ci.usage(nil, cores[i], row)
+		}
+
+		rows[i] = row
+	}
+
+	return rows, cores, nil
+}
+
+// collectStat sets context switches and interrupts per second
+// and returns the current counters.
+func (ci *cpuInfo) collectStat(data map[string]any) (cpuStat, error) {
+	now := time.Now()
+
+	ctxt, intr, err := readProcStat()
+	if err != nil {
+		return cpuStat{}, fmt.Errorf("read kernel statistics: %w", err)
+	}
+
+	data["cpu_ctxt_rate"] = float64(0)
+	data["cpu_intr_rate"] = float64(0)
+
+	if ci.ok {
+		if elapsed := now.Sub(ci.stat.time).Seconds(); elapsed > 0 {
+			data["cpu_ctxt_rate"] = math.Round(float64(counterDelta(ctxt, ci.stat.ctxt))/elapsed*100) / 100
+			data["cpu_intr_rate"] = math.Round(float64(counterDelta(intr, ci.stat.intr))/elapsed*100) / 100
+		}
+	}
+
+	return cpuStat{time: now, ctxt: ctxt, intr: intr}, nil
+}
+
+// readProcStat returns the total number of context switches and interrupts.
+func readProcStat() (uint64, uint64, error) {
+	file, err := os.Open(procStatPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	var ctxt, intr uint64
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "ctxt":
+			ctxt, _ = strconv.ParseUint(fields[1], 10, 64)
+		case "intr":
+			// First value is the total, others are per interrupt
+			intr, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	return ctxt, intr, nil
 }
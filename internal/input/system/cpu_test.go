@@ -0,0 +1,111 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/stretchr/testify/require"
+)
+
+func testCpu_Setup(t *testing.T) (*[]cpu.TimesStat, string) {
+	stdCpuTimes, stdStatPath := getCpuTimes, procStatPath
+	t.Cleanup(func() {
+		getCpuTimes, procStatPath = stdCpuTimes, stdStatPath
+	})
+
+	var cores []cpu.TimesStat
+	getCpuTimes = func(percpu bool) ([]cpu.TimesStat, error) {
+		if percpu {
+			return cores, nil
+		}
+
+		total := cpu.TimesStat{CPU: "cpu-total"}
+		for _, c := range cores {
+			total.User += c.User
+			total.System += c.System
+			total.Idle += c.Idle
+			total.Iowait += c.Iowait
+			total.Steal += c.Steal
+		}
+		return []cpu.TimesStat{total}, nil
+	}
+
+	procStatPath = filepath.Join(t.TempDir(), "stat")
+
+	return &cores, procStatPath
+}
+
+func TestCpuInfo_collect(t *testing.T) {
+	cores, statPath := testCpu_Setup(t)
+
+	*cores = []cpu.TimesStat{
+		{CPU: "cpu0", User: 100, System: 50, Idle: 850},
+		{CPU: "cpu1", User: 100, System: 50, Idle: 850},
+	}
+	stat := "cpu  200 0 100 1700 0 0 0 0 0 0\nintr 1000 10 20\nctxt 5000\n"
+	require.NoError(t, os.WriteFile(statPath, []byte(stat), 0644))
+
+	ci := &cpuInfo{PerCore: true, Breakdown: true}
+
+	data := map[string]any{"time": int64(1)}
+	rows, err := ci.collect(data)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), data["cpu_usage"])
+	require.Equal(t, float64(0), data["cpu_ctxt_rate"])
+	require.Len(t, rows, 2)
+
+	// cpu0 is saturated in user mode, cpu1 waits for I/O and steal
+	*cores = []cpu.TimesStat{
+		{CPU: "cpu0", User: 200, System: 50, Idle: 850},
+		{CPU: "cpu1", User: 100, System: 50, Idle: 880, Iowait: 50, Steal: 20},
+	}
+
+	// Failed pass keeps the row and the previous counters unchanged
+	require.NoError(t, os.Remove(statPath))
+	data = map[string]any{"time": int64(2)}
+	_, err = ci.collect(data)
+	require.ErrorContains(t, err, "read kernel statistics")
+	require.Equal(t, map[string]any{"time": int64(2)}, data)
+
+	stat = "cpu  300 0 100 1730 50 0 0 20 0 0\nintr 3000 10 20\nctxt 9000\n"
+	require.NoError(t, os.WriteFile(statPath, []byte(stat), 0644))
+
+	data = map[string]any{"time": int64(2)}
+	rows, err = ci.collect(data)
+	require.NoError(t, err)
+
+	require.Equal(t, float64(60), data["cpu_usage"])
+	require.Equal(t, float64(50), data["cpu_user"])
+	require.Equal(t, float64(0), data["cpu_system"])
+	require.Equal(t, float64(25), data["cpu_iowait"])
+	require.Equal(t, float64(10), data["cpu_steal"])
+	require.Greater(t, data["cpu_ctxt_rate"], float64(0))
+	require.Greater(t, data["cpu_intr_rate"], float64(0))
+
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(0), rows[0]["cpu_core"])
+	require.Equal(t, int64(2), rows[0]["time"])
+	require.Equal(t, float64(100), rows[0]["cpu_usage"], "single core saturation should be visible")
+	require.Equal(t, int64(1), rows[1]["cpu_core"])
+	require.Equal(t, float64(20), rows[1]["cpu_usage"])
+	require.Equal(t, float64(50), rows[1]["cpu_iowait"])
+	require.Equal(t, float64(20), rows[1]["cpu_steal"])
+}
+
+func TestCpuInfo_fields(t *testing.T) {
+	names := func(ci *cpuInfo) []string {
+		var result []string
+		for _, f := range ci.fields() {
+			result = append(result, f.Name)
+		}
+		return result
+	}
+
+	require.NotContains(t, names(&cpuInfo{}), "cpu_user")
+	require.NotContains(t, names(&cpuInfo{}), "cpu_core")
+	require.Contains(t, names(&cpuInfo{Breakdown: true}), "cpu_iowait")
+	require.Contains(t, names(&cpuInfo{Breakdown: true}), "cpu_ctxt_rate")
+	require.Contains(t, names(&cpuInfo{PerCore: true}), "cpu_core")
+}
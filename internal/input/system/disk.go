@@ -2,13 +2,16 @@ package system
 
 import (
 	"fmt"
+	"log"
 	"math"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/fugo-app/fugo/internal/field"
 	"github.com/shirou/gopsutil/v4/disk"
+	"gopkg.in/yaml.v3"
 )
 
 var diskFields = []*field.Field{
@@ -17,6 +20,11 @@ var diskFields = []*field.Field{
 		Type:        "string",
 		Description: "Disk device name",
 	},
+	{
+		Name:        "disk_path",
+		Type:        "string",
+		Description: "Disk mount point",
+	},
 	{
 		Name:        "disk_usage",
 		Type:        "float",
@@ -27,6 +35,16 @@ var diskFields = []*field.Field{
 		Type:        "int",
 		Description: "Disk total size in bytes",
 	},
+	{
+		Name:        "disk_free",
+		Type:        "int",
+		Description: "Disk free size in bytes",
+	},
+	{
+		Name:        "disk_inodes_usage",
+		Type:        "float",
+		Description: "Inode usage percentage",
+	},
 	{
 		Name:        "disk_read_bytes",
 		Type:        "int",
@@ -37,40 +55,225 @@ var diskFields = []*field.Field{
 		Type:        "int",
 		Description: "Delta of write bytes",
 	},
+	{
+		Name:        "disk_read_ops",
+		Type:        "int",
+		Description: "Delta of read operations",
+	},
+	{
+		Name:        "disk_write_ops",
+		Type:        "int",
+		Description: "Delta of write operations",
+	},
 }
 
-type diskInfo struct {
-	Path string `yaml:"path"`
+// getPartitions returns the list of physical partitions.
+var getPartitions = func() ([]disk.PartitionStat, error) {
+	return disk.Partitions(false)
+}
+
+// diskList is a list of disks to monitor.
+// Accepts a single disk or a list of disks:
+//
+//	disk:
+//	  path: /
+//
+//	disk:
+//	  - path: /
+//	  - path: /var/lib/postgresql
+//
+// The single disk is reported in the main row with other metrics.
+// Disks from the list are reported as a separate row per device with the "time" field.
+// Collector options (enabled, interval) are defined with the single disk or "all" path.
+type diskList struct {
+	collectorConfig
+
+	items  []*diskInfo
+	single bool
+}
 
-	dev     string
-	ok      bool
-	ioRead  uint64
-	ioWrite uint64
+func (dl *diskList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.MappingNode:
+		di := &diskInfo{}
+		if err := value.Decode(di); err != nil {
+			return err
+		}
+		if err := value.Decode(&dl.collectorConfig); err != nil {
+			return err
+		}
+		dl.items = []*diskInfo{di}
+		dl.single = di.Path != diskAll
+	case yaml.SequenceNode:
+		if err := value.Decode(&dl.items); err != nil {
+			return err
+		}
+		dl.single = false
+	default:
+		return fmt.Errorf("disk should be a mapping or a list")
+	}
+
+	return nil
 }
 
-func (di *diskInfo) init() error {
-	partitions, err := disk.Partitions(false)
+func (dl *diskList) fields() []*field.Field {
+	return diskFields
+}
+
+func (dl *diskList) init() error {
+	partitions, err := getPartitions()
 	if err != nil {
 		return fmt.Errorf("get partitions: %w", err)
 	}
 
+	var items []*diskInfo
+
+	for _, di := range dl.items {
+		if di.Path == "" {
+			return fmt.Errorf("disk path is required")
+		}
+
+		if di.Path == diskAll {
+			items = append(items, di.expand(partitions)...)
+			continue
+		}
+
+		di.init(partitions)
+		items = append(items, di)
+	}
+
+	dl.items = items
+
+	return nil
+}
+
+// collect adds the disk metrics to the main row or returns rows per device.
+func (dl *diskList) collect(data map[string]any) ([]map[string]any, error) {
+	if dl == nil {
+		return nil, nil
+	}
+
+	if dl.single {
+		return nil, dl.items[0].collect(data)
+	}
+
+	rows := make([]map[string]any, 0, len(dl.items))
+	for _, di := range dl.items {
+		row := map[string]any{
+			"time": data["time"],
+		}
+
+		if err := di.collect(row); err != nil {
+			log.Printf("Error on collecting disk status (%s): %v\n", di.Path, err)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// diskAll is a special path to monitor all physical mount points.
+const diskAll = "all"
+
+type diskInfo struct {
+	// Mount point or any path on the disk.
+	// Use "all" to monitor all physical mount points.
+	Path string `yaml:"path"`
+
+	// File systems to include for the "all" path.
+	// Example: ["ext4", "xfs"]
+	Fstype []string `yaml:"fstype,omitempty"`
+
+	// File systems to exclude for the "all" path.
+	// Example: ["squashfs"]
+	ExcludeFstype []string `yaml:"exclude_fstype,omitempty"`
+
+	dev      string
+	ok       bool
+	ioRead   uint64
+	ioWrite  uint64
+	opsRead  uint64
+	opsWrite uint64
+}
+
+// expand returns the list of disks for the all physical mount points.
+// Each device is reported once with the shortest mount point.
+func (di *diskInfo) expand(partitions []disk.PartitionStat) []*diskInfo {
+	partitions = slices.Clone(partitions)
+	sort.SliceStable(partitions, func(i, j int) bool {
+		return len(partitions[i].Mountpoint) < len(partitions[j].Mountpoint)
+	})
+
+	var items []*diskInfo
+	devices := make(map[string]struct{})
+
+	for _, p := range partitions {
+		if len(di.Fstype) > 0 && !slices.Contains(di.Fstype, p.Fstype) {
+			continue
+		}
+
+		if slices.Contains(di.ExcludeFstype, p.Fstype) {
+			continue
+		}
+
+		if _, ok := devices[p.Device]; ok {
+			continue
+		}
+		devices[p.Device] = struct{}{}
+
+		items = append(items, &diskInfo{
+			Path: p.Mountpoint,
+			dev:  filepath.Base(p.Device),
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+
+	return items
+}
+
+func (di *diskInfo) init(partitions []disk.PartitionStat) {
+	partitions = slices.Clone(partitions)
 	sort.Slice(partitions, func(i, j int) bool {
 		return len(partitions[i].Mountpoint) > len(partitions[j].Mountpoint)
 	})
 
 	for _, p := range partitions {
-		if strings.HasPrefix(di.Path, p.Mountpoint) {
+		if isSubpath(di.Path, p.Mountpoint) {
 			di.dev = filepath.Base(p.Device)
 			break
 		}
 	}
+}
 
-	return nil
+// isSubpath checks if the path is located on the mount point.
+func isSubpath(path string, mountpoint string) bool {
+	if path == mountpoint || mountpoint == "/" {
+		return true
+	}
+
+	return strings.HasPrefix(path, strings.TrimSuffix(mountpoint, "/")+"/")
+}
+
+// counterDelta returns the difference between counter values.
+// If the counter has been reset, the current value is used as the delta.
+func counterDelta(current uint64, previous uint64) int64 {
+	if current < previous {
+		return int64(current)
+	}
+
+	return int64(current - previous)
 }
 
 func (di *diskInfo) getIO(data map[string]any) error {
 	data["disk_read_bytes"] = int64(0)
 	data["disk_write_bytes"] = int64(0)
+	data["disk_read_ops"] = int64(0)
+	data["disk_write_ops"] = int64(0)
 
 	if di.dev == "" {
 		return nil
@@ -87,14 +290,18 @@ func (di *diskInfo) getIO(data map[string]any) error {
 	}
 
 	if di.ok {
-		data["disk_read_bytes"] = int64(diskIO.ReadBytes - di.ioRead)
-		data["disk_write_bytes"] = int64(diskIO.WriteBytes - di.ioWrite)
+		data["disk_read_bytes"] = counterDelta(diskIO.ReadBytes, di.ioRead)
+		data["disk_write_bytes"] = counterDelta(diskIO.WriteBytes, di.ioWrite)
+		data["disk_read_ops"] = counterDelta(diskIO.ReadCount, di.opsRead)
+		data["disk_write_ops"] = counterDelta(diskIO.WriteCount, di.opsWrite)
 	} else {
 		di.ok = true
 	}
 
 	di.ioRead = diskIO.ReadBytes
 	di.ioWrite = diskIO.WriteBytes
+	di.opsRead = diskIO.ReadCount
+	di.opsWrite = diskIO.WriteCount
 
 	return nil
 }
@@ -106,9 +313,12 @@ func (di *diskInfo) collect(data map[string]any) error {
 	}
 
 	data["disk_dev"] = di.dev
+	data["disk_path"] = di.Path
 
 	data["disk_usage"] = math.Round(diskStat.UsedPercent*100) / 100
 	data["disk_total"] = int64(diskStat.Total)
+	data["disk_free"] = int64(diskStat.Free)
+	data["disk_inodes_usage"] = math.Round(diskStat.InodesUsedPercent*100) / 100
 
 	if err := di.getIO(data); err != nil {
 		return fmt.Errorf("get disk io: %w", err)
@@ -0,0 +1,112 @@
+package system
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testDisk_Partitions(t *testing.T) {
+	stdPartitions := getPartitions
+	t.Cleanup(func() { getPartitions = stdPartitions })
+
+	getPartitions = func() ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "xfs"},
+			{Device: "/dev/sdb1", Mountpoint: "/var/lib/docker", Fstype: "xfs"},
+			{Device: "/dev/sdc1", Mountpoint: "/data/wal", Fstype: "ext4"},
+			{Device: "/dev/loop0", Mountpoint: "/snap/core", Fstype: "squashfs"},
+		}, nil
+	}
+}
+
+func TestDiskList_UnmarshalYAML(t *testing.T) {
+	var sw SystemWatcher
+
+	require.NoError(t, yaml.Unmarshal([]byte("disk:\n  path: /\n"), &sw))
+	require.True(t, sw.Disk.single, "single disk should be reported in the main row")
+	require.Len(t, sw.Disk.items, 1)
+	require.Equal(t, "/", sw.Disk.items[0].Path)
+
+	sw = SystemWatcher{}
+	require.NoError(t, yaml.Unmarshal([]byte("disk:\n  - path: /\n  - path: /data\n"), &sw))
+	require.False(t, sw.Disk.single, "disks from the list should be reported per device")
+	require.Len(t, sw.Disk.items, 2)
+
+	sw = SystemWatcher{}
+	require.NoError(t, yaml.Unmarshal([]byte("disk:\n  path: all\n  fstype: [ext4]\n"), &sw))
+	require.False(t, sw.Disk.single, "all disks should be reported per device")
+	require.Equal(t, []string{"ext4"}, sw.Disk.items[0].Fstype)
+
+	sw = SystemWatcher{}
+	require.Error(t, yaml.Unmarshal([]byte("disk: /\n"), &sw))
+}
+
+func TestDiskList_init(t *testing.T) {
+	testDisk_Partitions(t)
+
+	tests := []struct {
+		name  string
+		items []*diskInfo
+		want  map[string]string
+	}{
+		{
+			name: "explicit paths",
+			items: []*diskInfo{
+				{Path: "/"},
+				{Path: "/data/wal/pg_wal"},
+				{Path: "/datastore"},
+			},
+			want: map[string]string{
+				"/":                "sda1",
+				"/data/wal/pg_wal": "sdc1",
+				"/datastore":       "sda1",
+			},
+		},
+		{
+			name: "all physical mounts",
+			items: []*diskInfo{
+				{Path: "all", ExcludeFstype: []string{"squashfs"}},
+			},
+			want: map[string]string{
+				"/":         "sda1",
+				"/data":     "sdb1",
+				"/data/wal": "sdc1",
+			},
+		},
+		{
+			name: "all with fstype filter",
+			items: []*diskInfo{
+				{Path: "all", Fstype: []string{"xfs"}},
+			},
+			want: map[string]string{
+				"/data": "sdb1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dl := &diskList{items: tt.items}
+			require.NoError(t, dl.init())
+
+			got := make(map[string]string)
+			for _, di := range dl.items {
+				got[di.Path] = di.dev
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	dl := &diskList{items: []*diskInfo{{}}}
+	require.Error(t, dl.init(), "disk path is required")
+}
+
+func TestCounterDelta(t *testing.T) {
+	require.Equal(t, int64(100), counterDelta(1100, 1000))
+	require.Equal(t, int64(0), counterDelta(1000, 1000))
+	require.Equal(t, int64(50), counterDelta(50, 1000), "reset counter should use the current value")
+}
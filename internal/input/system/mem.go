@@ -1,8 +1,16 @@
 package system
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fugo-app/fugo/internal/field"
 	"github.com/shirou/gopsutil/v4/mem"
@@ -21,10 +29,188 @@ var memFields = []*field.Field{
 	},
 }
 
-func collectMem(data map[string]any) error {
+var memSwapFields = []*field.Field{
+	{
+		Name:        "mem_swap_usage",
+		Type:        "float",
+		Description: "Swap usage percentage",
+	},
+	{
+		Name:        "mem_swap_total",
+		Type:        "int",
+		Description: "Swap total size in bytes",
+	},
+	{
+		Name:        "mem_swap_in_rate",
+		Type:        "float",
+		Description: "Bytes swapped in per second",
+	},
+	{
+		Name:        "mem_swap_out_rate",
+		Type:        "float",
+		Description: "Bytes swapped out per second",
+	},
+}
+
+var memCacheFields = []*field.Field{
+	{
+		Name:        "mem_cached",
+		Type:        "int",
+		Description: "Page cache size in bytes",
+	},
+	{
+		Name:        "mem_buffers",
+		Type:        "int",
+		Description: "Block device buffers size in bytes",
+	},
+	{
+		Name:        "mem_dirty",
+		Type:        "int",
+		Description: "Memory waiting to be written to disk in bytes",
+	},
+	{
+		Name:        "mem_slab",
+		Type:        "int",
+		Description: "Kernel slab size in bytes",
+	},
+}
+
+var memHugepagesFields = []*field.Field{
+	{
+		Name:        "mem_hugepages_total",
+		Type:        "int",
+		Description: "Number of huge pages",
+	},
+	{
+		Name:        "mem_hugepages_free",
+		Type:        "int",
+		Description: "Number of free huge pages",
+	},
+	{
+		Name:        "mem_hugepages_size",
+		Type:        "int",
+		Description: "Huge page size in bytes",
+	},
+}
+
+var memFaultsFields = []*field.Field{
+	{
+		Name:        "mem_major_faults",
+		Type:        "int",
+		Description: "Delta of major page faults",
+	},
+	{
+		Name:        "mem_minor_faults",
+		Type:        "int",
+		Description: "Delta of minor page faults",
+	},
+}
+
+// Pressure stall resources and their kinds.
+var psiResources = []struct {
+	name  string
+	kinds []string
+}{
+	{"cpu", []string{"some"}},
+	{"memory", []string{"some", "full"}},
+	{"io", []string{"some", "full"}},
+}
+
+var memPressureFields = func() []*field.Field {
+	var fields []*field.Field
+
+	for _, res := range psiResources {
+		for _, kind := range res.kinds {
+			prefix := "psi_" + res.name + "_" + kind
+			fields = append(fields,
+				&field.Field{
+					Name:        prefix + "_avg10",
+					Type:        "float",
+					Description: fmt.Sprintf("Share of time %s tasks stalled on %s in the last 10 seconds", kind, res.name),
+				},
+				&field.Field{
+					Name:        prefix + "_avg60",
+					Type:        "float",
+					Description: fmt.Sprintf("Share of time %s tasks stalled on %s in the last 60 seconds", kind, res.name),
+				},
+				&field.Field{
+					Name:        prefix + "_total",
+					Type:        "int",
+					Description: fmt.Sprintf("Delta of time %s tasks stalled on %s in microseconds", kind, res.name),
+				},
+			)
+		}
+	}
+
+	return fields
+}()
+
+// Paths to the kernel memory statistics.
+var (
+	procMeminfoPath  = "/proc/meminfo"
+	procVmstatPath   = "/proc/vmstat"
+	procPressurePath = "/proc/pressure"
+)
+
+type memInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Report swap usage and swap in/out rates.
+	Swap bool `yaml:"swap,omitempty"`
+
+	// Report page cache, buffers, dirty, and slab sizes.
+	Cache bool `yaml:"cache,omitempty"`
+
+	// Report huge pages.
+	Hugepages bool `yaml:"hugepages,omitempty"`
+
+	// Report major and minor page faults.
+	Faults bool `yaml:"faults,omitempty"`
+
+	// Report pressure stall information from /proc/pressure.
+	Pressure bool `yaml:"pressure,omitempty"`
+
+	ok     bool
+	time   time.Time
+	vmstat map[string]uint64
+	psi    map[string]uint64
+}
+
+func (mi *memInfo) init() error {
+	return nil
+}
+
+func (mi *memInfo) fields() []*field.Field {
+	fields := make([]*field.Field, 0)
+	fields = append(fields, memFields...)
+
+	if mi.Swap {
+		fields = append(fields, memSwapFields...)
+	}
+
+	if mi.Cache {
+		fields = append(fields, memCacheFields...)
+	}
+
+	if mi.Hugepages {
+		fields = append(fields, memHugepagesFields...)
+	}
+
+	if mi.Faults {
+		fields = append(fields, memFaultsFields...)
+	}
+
+	if mi.Pressure {
+		fields = append(fields, memPressureFields...)
+	}
+
+	return fields
+}
+
+func (mi *memInfo) collect(data map[string]any) ([]map[string]any, error) {
 	memStat, err := mem.VirtualMemory()
 	if err != nil {
-		return fmt.Errorf("get memory status: %w", err)
+		return nil, fmt.Errorf("get memory status: %w", err)
 	}
 
 	used := float64(memStat.Total - memStat.Available)
@@ -33,5 +219,223 @@ func collectMem(data map[string]any) error {
 
 	data["mem_total"] = int64(memStat.Total)
 
+	if err := mi.collectExtended(data); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// collectExtended sets the optional field groups.
+func (mi *memInfo) collectExtended(data map[string]any) error {
+	now := time.Now()
+	elapsed := now.Sub(mi.time).Seconds()
+
+	if mi.Swap || mi.Cache || mi.Hugepages {
+		meminfo, err := readMeminfo()
+		if err != nil {
+			return fmt.Errorf("read memory info: %w", err)
+		}
+
+		if mi.Swap {
+			swapTotal := meminfo["SwapTotal"]
+			swapUsed := swapTotal - min(meminfo["SwapFree"], swapTotal)
+			data["mem_swap_usage"] = percent(float64(swapUsed), float64(swapTotal))
+			data["mem_swap_total"] = int64(swapTotal)
+		}
+
+		if mi.Cache {
+			data["mem_cached"] = int64(meminfo["Cached"])
+			data["mem_buffers"] = int64(meminfo["Buffers"])
+			data["mem_dirty"] = int64(meminfo["Dirty"])
+			data["mem_slab"] = int64(meminfo["Slab"])
+		}
+
+		if mi.Hugepages {
+			data["mem_hugepages_total"] = int64(meminfo["HugePages_Total"])
+			data["mem_hugepages_free"] = int64(meminfo["HugePages_Free"])
+			data["mem_hugepages_size"] = int64(meminfo["Hugepagesize"])
+		}
+	}
+
+	if mi.Swap || mi.Faults {
+		vmstat, err := readVmstat()
+		if err != nil {
+			return fmt.Errorf("read virtual memory statistics: %w", err)
+		}
+
+		delta := func(key string) int64 {
+			if !mi.ok {
+				return 0
+			}
+			return counterDelta(vmstat[key], mi.vmstat[key])
+		}
+
+		if mi.Swap {
+			pageSize := float64(os.Getpagesize())
+			data["mem_swap_in_rate"] = float64(0)
+			data["mem_swap_out_rate"] = float64(0)
+			if mi.ok && elapsed > 0 {
+				data["mem_swap_in_rate"] = math.Round(float64(delta("pswpin"))*pageSize/elapsed*100) / 100
+				data["mem_swap_out_rate"] = math.Round(float64(delta("pswpout"))*pageSize/elapsed*100) / 100
+			}
+		}
+
+		if mi.Faults {
+			major := delta("pgmajfault")
+			data["mem_major_faults"] = major
+			data["mem_minor_faults"] = max(delta("pgfault")-major, 0)
+		}
+
+		mi.vmstat = vmstat
+	}
+
+	if mi.Pressure {
+		psi := make(map[string]uint64)
+
+		for _, res := range psiResources {
+			if err := readPressure(res.name, res.kinds, data, psi); err != nil {
+				return fmt.Errorf("read %s pressure: %w", res.name, err)
+			}
+		}
+
+		for key, val := range psi {
+			name := key + "_total"
+			if mi.ok {
+				data[name] = counterDelta(val, mi.psi[key])
+			} else {
+				data[name] = int64(0)
+			}
+		}
+
+		mi.psi = psi
+	}
+
+	mi.ok = true
+	mi.time = now
+
+	return nil
+}
+
+// readMeminfo returns the memory information in bytes.
+// Huge page counters are returned as is.
+func readMeminfo() (map[string]uint64, error) {
+	file, err := os.Open(procMeminfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+
+		val, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if len(fields) > 1 && fields[1] == "kB" {
+			val *= 1024
+		}
+
+		result[key] = val
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readVmstat returns the virtual memory counters.
+func readVmstat() (map[string]uint64, error) {
+	file, err := os.Open(procVmstatPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		if val, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			result[fields[0]] = val
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readPressure sets averages for the resource and returns total stall time.
+// Line format: "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+// Kernels without pressure stall information report zeros.
+func readPressure(name string, kinds []string, data map[string]any, totals map[string]uint64) error {
+	for _, kind := range kinds {
+		prefix := "psi_" + name + "_" + kind
+		data[prefix+"_avg10"] = float64(0)
+		data[prefix+"_avg60"] = float64(0)
+		totals[prefix] = 0
+	}
+
+	content, err := os.ReadFile(filepath.Join(procPressurePath, name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		prefix := "psi_" + name + "_" + fields[0]
+		if _, ok := totals[prefix]; !ok {
+			continue
+		}
+
+		for _, item := range fields[1:] {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				continue
+			}
+
+			switch key {
+			case "avg10", "avg60":
+				if val, err := strconv.ParseFloat(value, 64); err == nil {
+					data[prefix+"_"+key] = val
+				}
+			case "total":
+				if val, err := strconv.ParseUint(value, 10, 64); err == nil {
+					totals[prefix] = val
+				}
+			}
+		}
+	}
+
 	return nil
 }
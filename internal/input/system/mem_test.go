@@ -0,0 +1,101 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMem_Setup(t *testing.T) string {
+	stdMeminfo, stdVmstat, stdPressure := procMeminfoPath, procVmstatPath, procPressurePath
+	t.Cleanup(func() {
+		procMeminfoPath, procVmstatPath, procPressurePath = stdMeminfo, stdVmstat, stdPressure
+	})
+
+	tempDir := t.TempDir()
+	procMeminfoPath = filepath.Join(tempDir, "meminfo")
+	procVmstatPath = filepath.Join(tempDir, "vmstat")
+	procPressurePath = filepath.Join(tempDir, "pressure")
+	require.NoError(t, os.Mkdir(procPressurePath, 0755))
+
+	meminfo := "MemTotal:        8000000 kB\n" +
+		"Buffers:            1000 kB\n" +
+		"Cached:           200000 kB\n" +
+		"SwapTotal:       1000000 kB\n" +
+		"SwapFree:         750000 kB\n" +
+		"Dirty:               100 kB\n" +
+		"Slab:              50000 kB\n" +
+		"HugePages_Total:      16\n" +
+		"HugePages_Free:        4\n" +
+		"Hugepagesize:       2048 kB\n"
+	require.NoError(t, os.WriteFile(procMeminfoPath, []byte(meminfo), 0644))
+
+	testMem_Write(t, "vmstat", "pgfault 1000\npgmajfault 10\npswpin 0\npswpout 0\n")
+	testMem_Write(t, "pressure/cpu", "some avg10=1.50 avg60=0.75 avg300=0.10 total=1000\n")
+	testMem_Write(t, "pressure/memory", "some avg10=0.00 avg60=0.00 avg300=0.00 total=500\n"+
+		"full avg10=0.00 avg60=0.00 avg300=0.00 total=200\n")
+	// No "io" pressure file
+
+	return tempDir
+}
+
+func testMem_Write(t *testing.T, name string, content string) {
+	path := filepath.Join(filepath.Dir(procMeminfoPath), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestMemInfo_collectExtended(t *testing.T) {
+	testMem_Setup(t)
+
+	mi := &memInfo{Swap: true, Cache: true, Hugepages: true, Faults: true, Pressure: true}
+
+	data := make(map[string]any)
+	require.NoError(t, mi.collectExtended(data))
+
+	require.Equal(t, float64(25), data["mem_swap_usage"])
+	require.Equal(t, int64(1000000*1024), data["mem_swap_total"])
+	require.Equal(t, float64(0), data["mem_swap_in_rate"])
+	require.Equal(t, int64(200000*1024), data["mem_cached"])
+	require.Equal(t, int64(1000*1024), data["mem_buffers"])
+	require.Equal(t, int64(100*1024), data["mem_dirty"])
+	require.Equal(t, int64(50000*1024), data["mem_slab"])
+	require.Equal(t, int64(16), data["mem_hugepages_total"])
+	require.Equal(t, int64(4), data["mem_hugepages_free"])
+	require.Equal(t, int64(2048*1024), data["mem_hugepages_size"])
+	require.Equal(t, int64(0), data["mem_major_faults"])
+	require.Equal(t, 1.5, data["psi_cpu_some_avg10"])
+	require.Equal(t, 0.75, data["psi_cpu_some_avg60"])
+	require.Equal(t, int64(0), data["psi_cpu_some_total"])
+	require.Equal(t, float64(0), data["psi_io_full_avg10"], "missing pressure should be reported as zero")
+
+	testMem_Write(t, "vmstat", "pgfault 1500\npgmajfault 30\npswpin 10\npswpout 20\n")
+	testMem_Write(t, "pressure/cpu", "some avg10=2.00 avg60=1.00 avg300=0.20 total=4000\n")
+
+	data = make(map[string]any)
+	require.NoError(t, mi.collectExtended(data))
+
+	require.Equal(t, int64(20), data["mem_major_faults"])
+	require.Equal(t, int64(480), data["mem_minor_faults"])
+	require.Greater(t, data["mem_swap_in_rate"], float64(0))
+	require.Greater(t, data["mem_swap_out_rate"], data["mem_swap_in_rate"])
+	require.Equal(t, int64(3000), data["psi_cpu_some_total"])
+	require.Equal(t, int64(0), data["psi_memory_full_total"])
+
+	// Every field of the enabled groups is reported
+	for _, f := range mi.fields()[len(memFields):] {
+		require.Contains(t, data, f.Name)
+	}
+}
+
+func TestMemInfo_Disabled(t *testing.T) {
+	testMem_Setup(t)
+
+	mi := &memInfo{}
+	require.Equal(t, memFields, mi.fields())
+
+	data := make(map[string]any)
+	require.NoError(t, mi.collectExtended(data))
+	require.Empty(t, data)
+}
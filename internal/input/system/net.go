@@ -3,13 +3,19 @@ package system
 import (
 	"bufio"
 	"fmt"
+	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fugo-app/fugo/internal/field"
+	"gopkg.in/yaml.v3"
 )
 
 var netFields = []*field.Field{
@@ -58,28 +64,103 @@ var netFields = []*field.Field{
 		Type:        "int",
 		Description: "Delta of dropped outgoing packets",
 	},
+	{
+		Name:        "net_rx_bytes_rate",
+		Type:        "float",
+		Description: "Received bytes per second",
+	},
+	{
+		Name:        "net_tx_bytes_rate",
+		Type:        "float",
+		Description: "Transmitted bytes per second",
+	},
+	{
+		Name:        "net_rx_packets_rate",
+		Type:        "float",
+		Description: "Received packets per second",
+	},
+	{
+		Name:        "net_tx_packets_rate",
+		Type:        "float",
+		Description: "Transmitted packets per second",
+	},
+}
+
+// Counters to read from the interface statistics.
+var netCounters = []string{
+	"rx_bytes",
+	"tx_bytes",
+	"rx_packets",
+	"tx_packets",
+	"rx_errors",
+	"tx_errors",
+	"rx_dropped",
+	"tx_dropped",
+}
+
+// Counters to report per second.
+var netRates = []string{
+	"rx_bytes",
+	"tx_bytes",
+	"rx_packets",
+	"tx_packets",
+}
+
+// Path to the network interfaces in sysfs.
+var netSysPath = "/sys/class/net"
+
+// Path to the kernel routing table.
+var netRoutePath = "/proc/net/route"
+
+// stringList is a list of strings. Accepts a single string or a list of strings.
+type stringList []string
+
+func (sl *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*sl = stringList{value.Value}
+		return nil
+	}
+
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*sl = list
+
+	return nil
 }
 
 type netInfo struct {
-	Interface string `yaml:"interface,omitempty"`
+	collectorConfig `yaml:",inline"`
 
-	initialized bool
+	// Interface names or glob patterns. "default" is the interface with the default route.
+	// Default: "default"
+	// Example: "eth0" or ["eth*", "ens*"]
+	Interface stringList `yaml:"interface,omitempty"`
 
-	ifname     string
-	rx_bytes   int64
-	tx_bytes   int64
-	rx_packets int64
-	tx_packets int64
-	rx_errors  int64
-	tx_errors  int64
-	rx_dropped int64
-	tx_dropped int64
+	// Glob patterns to exclude interfaces.
+	// Example: ["veth*", "docker*", "br-*"]
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Single interface is reported in the main row with other metrics.
+	// Multiple interfaces or patterns are reported as a separate row per interface.
+	single bool
+
+	patterns []string
+	state    map[string]*netState
+}
+
+// netState keeps the previous counters of the interface.
+type netState struct {
+	index    string
+	time     time.Time
+	counters map[string]uint64
 }
 
 var re = regexp.MustCompile(`^(\S+)\s+(\S+)`)
 
 func getDefaultInterface() (string, error) {
-	file, err := os.Open("/proc/net/route")
+	file, err := os.Open(netRoutePath)
 	if err != nil {
 		return "", fmt.Errorf("open route list: %w", err)
 	}
@@ -109,12 +190,19 @@ func getDefaultInterface() (string, error) {
 	return "", fmt.Errorf("default interface not found")
 }
 
-func netReadStat(iface string, key string) int64 {
-	path := filepath.Join("/sys/class/net", iface, "statistics", key)
+func netReadValue(iface string, name string) (string, bool) {
+	path := filepath.Join(netSysPath, iface, name)
 
 	if bval, err := os.ReadFile(path); err == nil {
-		sval := strings.TrimSpace(string(bval))
-		if nval, err := strconv.ParseInt(sval, 10, 64); err == nil {
+		return strings.TrimSpace(string(bval)), true
+	}
+
+	return "", false
+}
+
+func netReadStat(iface string, key string) uint64 {
+	if sval, ok := netReadValue(iface, filepath.Join("statistics", key)); ok {
+		if nval, err := strconv.ParseUint(sval, 10, 64); err == nil {
 			return nval
 		}
 	}
@@ -122,52 +210,164 @@ func netReadStat(iface string, key string) int64 {
 	return 0
 }
 
+func isPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+func (ni *netInfo) fields() []*field.Field {
+	return netFields
+}
+
 func (ni *netInfo) init() error {
-	if ni.Interface != "default" {
-		ni.ifname = ni.Interface
-	} else {
+	if len(ni.Interface) == 0 {
+		ni.Interface = stringList{"default"}
+	}
+
+	for _, pattern := range slices.Concat(ni.Exclude, ni.Interface) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid interface pattern (%s): %w", pattern, err)
+		}
+	}
+
+	ni.patterns = make([]string, 0, len(ni.Interface))
+	for _, name := range ni.Interface {
+		if name != "default" {
+			ni.patterns = append(ni.patterns, name)
+			continue
+		}
+
 		iface, err := getDefaultInterface()
 		if err != nil {
 			return fmt.Errorf("get default interface: %w", err)
 		}
-		ni.ifname = iface
+		ni.patterns = append(ni.patterns, iface)
 	}
 
+	ni.single = len(ni.patterns) == 1 && !isPattern(ni.patterns[0])
+	ni.state = make(map[string]*netState)
+
 	return nil
 }
 
-func (ni *netInfo) collect(data map[string]any) error {
-	data["net_if"] = ni.ifname
+// interfaces returns the list of interfaces matching the patterns.
+func (ni *netInfo) interfaces() []string {
+	if ni.single {
+		return ni.patterns
+	}
+
+	entries, err := os.ReadDir(netSysPath)
+	if err != nil {
+		log.Printf("Error on reading network interfaces: %v\n", err)
+		return nil
+	}
+
+	var result []string
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if matchAny(ni.patterns, name) && !matchAny(ni.Exclude, name) {
+			result = append(result, name)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collect adds the network metrics to the main row or returns rows per interface.
+func (ni *netInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if ni == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	if ni.single {
+		ni.collectInterface(ni.patterns[0], now, data)
+		return nil, nil
+	}
 
-	fields := []struct {
-		name string
-		ptr  *int64
-	}{
-		{"rx_bytes", &ni.rx_bytes},
-		{"tx_bytes", &ni.tx_bytes},
-		{"rx_packets", &ni.rx_packets},
-		{"tx_packets", &ni.tx_packets},
-		{"rx_errors", &ni.rx_errors},
-		{"tx_errors", &ni.tx_errors},
-		{"rx_dropped", &ni.rx_dropped},
-		{"tx_dropped", &ni.tx_dropped},
+	interfaces := ni.interfaces()
+	rows := make([]map[string]any, 0, len(interfaces))
+	active := make(map[string]struct{}, len(interfaces))
+
+	for _, iface := range interfaces {
+		active[iface] = struct{}{}
+
+		row := map[string]any{
+			"time": data["time"],
+		}
+		ni.collectInterface(iface, now, row)
+		rows = append(rows, row)
+	}
+
+	// Forget removed interfaces
+	for iface := range ni.state {
+		if _, ok := active[iface]; !ok {
+			delete(ni.state, iface)
+		}
 	}
 
-	for _, field := range fields {
-		val := netReadStat(ni.ifname, field.name)
+	return rows, nil
+}
+
+func (ni *netInfo) collectInterface(iface string, now time.Time, data map[string]any) {
+	data["net_if"] = iface
+
+	index, _ := netReadValue(iface, "ifindex")
+
+	prev := ni.state[iface]
+	if prev != nil && prev.index != index {
+		// Interface has been recreated, counters start from zero
+		prev = &netState{
+			index:    index,
+			time:     prev.time,
+			counters: make(map[string]uint64),
+		}
+	}
+
+	current := &netState{
+		index:    index,
+		time:     now,
+		counters: make(map[string]uint64, len(netCounters)),
+	}
+
+	for _, name := range netCounters {
+		val := netReadStat(iface, name)
+		current.counters[name] = val
+
 		delta := int64(0)
-		if ni.initialized {
-			delta = val - *field.ptr
+		if prev != nil {
+			delta = counterDelta(val, prev.counters[name])
 		}
-		*field.ptr = val
 
-		key := "net_" + field.name
-		data[key] = delta
+		data["net_"+name] = delta
 	}
 
-	if !ni.initialized {
-		ni.initialized = true
+	elapsed := float64(0)
+	if prev != nil {
+		elapsed = now.Sub(prev.time).Seconds()
 	}
 
-	return nil
+	for _, name := range netRates {
+		rate := float64(0)
+		if elapsed > 0 {
+			rate = float64(data["net_"+name].(int64)) / elapsed
+		}
+
+		data["net_"+name+"_rate"] = math.Round(rate*100) / 100
+	}
+
+	ni.state[iface] = current
 }
@@ -0,0 +1,122 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testNet_Setup(t *testing.T) string {
+	stdSysPath, stdRoutePath := netSysPath, netRoutePath
+	t.Cleanup(func() {
+		netSysPath, netRoutePath = stdSysPath, stdRoutePath
+	})
+
+	tempDir := t.TempDir()
+	netSysPath = filepath.Join(tempDir, "net")
+
+	netRoutePath = filepath.Join(tempDir, "route")
+	route := "Iface\tDestination\tGateway\n" +
+		"eth1\t0000A8C0\t00000000\n" +
+		"eth0\t00000000\t0101A8C0\n"
+	require.NoError(t, os.WriteFile(netRoutePath, []byte(route), 0644))
+
+	return tempDir
+}
+
+func testNet_SetInterface(t *testing.T, iface string, index int, rxBytes uint64) {
+	dir := filepath.Join(netSysPath, iface, "statistics")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	ifindex := filepath.Join(netSysPath, iface, "ifindex")
+	require.NoError(t, os.WriteFile(ifindex, []byte(strconv.Itoa(index)+"\n"), 0644))
+
+	for _, name := range netCounters {
+		val := uint64(0)
+		if name == "rx_bytes" {
+			val = rxBytes
+		}
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(strconv.FormatUint(val, 10)+"\n"), 0644))
+	}
+}
+
+func TestNetInfo_Default(t *testing.T) {
+	testNet_Setup(t)
+	testNet_SetInterface(t, "eth0", 2, 1000)
+
+	var sw SystemWatcher
+	require.NoError(t, yaml.Unmarshal([]byte("net:\n  interface: default\n"), &sw))
+	require.NoError(t, sw.Net.init())
+	require.True(t, sw.Net.single, "single interface should be reported in the main row")
+
+	data := make(map[string]any)
+	rows, err := sw.Net.collect(data)
+	require.NoError(t, err)
+	require.Nil(t, rows)
+	require.Equal(t, "eth0", data["net_if"])
+	require.Equal(t, int64(0), data["net_rx_bytes"])
+	require.Equal(t, float64(0), data["net_rx_bytes_rate"])
+
+	testNet_SetInterface(t, "eth0", 2, 1500)
+	data = make(map[string]any)
+	_, err = sw.Net.collect(data)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), data["net_rx_bytes"])
+	require.Greater(t, data["net_rx_bytes_rate"], float64(0))
+}
+
+func TestNetInfo_Patterns(t *testing.T) {
+	testNet_Setup(t)
+	testNet_SetInterface(t, "eth0", 2, 1000)
+	testNet_SetInterface(t, "eth1", 3, 2000)
+	testNet_SetInterface(t, "veth1a2b", 10, 3000)
+	testNet_SetInterface(t, "docker0", 4, 4000)
+
+	var sw SystemWatcher
+	config := "net:\n  interface: [\"eth*\", \"veth*\"]\n  exclude: [\"veth*\"]\n"
+	require.NoError(t, yaml.Unmarshal([]byte(config), &sw))
+	require.NoError(t, sw.Net.init())
+	require.False(t, sw.Net.single, "patterns should be reported per interface")
+
+	data := map[string]any{"time": int64(1)}
+	rows, err := sw.Net.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, "eth0", rows[0]["net_if"])
+	require.Equal(t, "eth1", rows[1]["net_if"])
+	require.Equal(t, int64(1), rows[0]["time"])
+	require.NotContains(t, data, "net_if", "main row should not contain interface metrics")
+
+	// Interface eth1 has been recreated with the new index
+	testNet_SetInterface(t, "eth0", 2, 1200)
+	testNet_SetInterface(t, "eth1", 5, 100)
+
+	rows, err = sw.Net.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(200), rows[0]["net_rx_bytes"])
+	require.Equal(t, int64(100), rows[1]["net_rx_bytes"], "recreated interface should count from zero")
+
+	// Counter reset on the same interface
+	testNet_SetInterface(t, "eth0", 2, 50)
+
+	rows, err = sw.Net.collect(data)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), rows[0]["net_rx_bytes"], "reset counter should not be negative")
+}
+
+func TestNetInfo_Init(t *testing.T) {
+	testNet_Setup(t)
+
+	ni := &netInfo{Interface: stringList{"eth["}}
+	require.Error(t, ni.init(), "invalid pattern")
+
+	ni = &netInfo{}
+	require.NoError(t, ni.init())
+	require.Equal(t, []string{"eth0"}, ni.patterns)
+}
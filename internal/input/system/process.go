@@ -0,0 +1,391 @@
+package system
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+var processFields = []*field.Field{
+	{
+		Name:        "proc_group",
+		Type:        "string",
+		Description: "Process group name or \"top\" for the top processes",
+	},
+	{
+		Name:        "proc_pid",
+		Type:        "int",
+		Description: "Process ID",
+	},
+	{
+		Name:        "proc_name",
+		Type:        "string",
+		Description: "Process name",
+	},
+	{
+		Name:        "proc_cmdline",
+		Type:        "string",
+		Description: "Process command line",
+	},
+	{
+		Name:        "proc_cpu",
+		Type:        "float",
+		Description: "Process CPU usage percentage of a single core",
+	},
+	{
+		Name:        "proc_rss",
+		Type:        "int",
+		Description: "Process resident memory size in bytes",
+	},
+	{
+		Name:        "proc_fds",
+		Type:        "int",
+		Description: "Number of open file descriptors",
+	},
+	{
+		Name:        "proc_threads",
+		Type:        "int",
+		Description: "Number of threads",
+	},
+	{
+		Name:        "proc_read_bytes",
+		Type:        "int",
+		Description: "Delta of bytes read from storage",
+	},
+	{
+		Name:        "proc_write_bytes",
+		Type:        "int",
+		Description: "Delta of bytes written to storage",
+	},
+}
+
+// procStat is a snapshot of the process.
+type procStat struct {
+	pid        int32
+	name       string
+	cmdline    string
+	createTime int64
+	cpuTime    float64 // User and system time in seconds
+
+	// Details are loaded for the selected processes only
+	rss        uint64
+	fds        int32
+	threads    int32
+	readBytes  uint64
+	writeBytes uint64
+	details    bool
+}
+
+// listProcesses returns the snapshot of all running processes.
+var listProcesses = func() ([]*procStat, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*procStat, 0, len(procs))
+	for _, p := range procs {
+		times, err := p.Times()
+		if err != nil {
+			// Process has exited
+			continue
+		}
+
+		ps := &procStat{
+			pid:     p.Pid,
+			cpuTime: times.User + times.System,
+		}
+		ps.name, _ = p.Name()
+		ps.cmdline, _ = p.Cmdline()
+		ps.createTime, _ = p.CreateTime()
+
+		result = append(result, ps)
+	}
+
+	return result, nil
+}
+
+// loadProcessDetails loads memory, descriptors, threads, and IO counters.
+// Some details require the same user or root privileges and remain zero otherwise.
+var loadProcessDetails = func(ps *procStat) {
+	p := &process.Process{Pid: ps.pid}
+
+	if mem, err := p.MemoryInfo(); err == nil {
+		ps.rss = mem.RSS
+	}
+
+	ps.fds, _ = p.NumFDs()
+	ps.threads, _ = p.NumThreads()
+
+	if io, err := p.IOCounters(); err == nil {
+		ps.readBytes = io.ReadBytes
+		ps.writeBytes = io.WriteBytes
+	}
+}
+
+// readProcessCgroup returns the content of the process cgroup file.
+var readProcessCgroup = func(pid int32) string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+type processInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Named process groups.
+	Groups []*processGroup `yaml:"groups,omitempty"`
+
+	// Number of processes with the highest CPU usage to report on each tick.
+	// Reported with the "top" group name.
+	// Default: 0 (disabled)
+	Top int `yaml:"top,omitempty"`
+
+	time  time.Time
+	state map[procKey]*procStat
+}
+
+// procKey identifies the process. Create time protects against PID reuse.
+type procKey struct {
+	pid        int32
+	createTime int64
+}
+
+type processGroup struct {
+	// Group name reported in the "proc_group" field.
+	Name string `yaml:"name"`
+
+	// Exact process name.
+	// Example: "nginx"
+	Process string `yaml:"process,omitempty"`
+
+	// Regular expression to match the process command line.
+	// Example: "java .*-jar /opt/app.jar"
+	Cmdline string `yaml:"cmdline,omitempty"`
+
+	// Path to the file with the process ID.
+	// Example: "/run/nginx.pid"
+	Pidfile string `yaml:"pidfile,omitempty"`
+
+	// Systemd unit name. Matches all processes of the unit.
+	// Example: "postgresql.service"
+	Unit string `yaml:"unit,omitempty"`
+
+	cmdline *regexp.Regexp
+}
+
+func (pg *processGroup) init() error {
+	if pg.Name == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	if pg.Process == "" && pg.Cmdline == "" && pg.Pidfile == "" && pg.Unit == "" {
+		return fmt.Errorf("group %s: process, cmdline, pidfile, or unit is required", pg.Name)
+	}
+
+	if pg.Cmdline != "" {
+		re, err := regexp.Compile(pg.Cmdline)
+		if err != nil {
+			return fmt.Errorf("group %s: invalid cmdline regex: %w", pg.Name, err)
+		}
+		pg.cmdline = re
+	}
+
+	return nil
+}
+
+// readPidfile returns the process ID from the pidfile or 0 if unavailable.
+func (pg *processGroup) readPidfile() int32 {
+	if pg.Pidfile == "" {
+		return 0
+	}
+
+	data, err := os.ReadFile(pg.Pidfile)
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int32(pid)
+}
+
+// match checks if the process belongs to the group. All defined conditions must match.
+func (pg *processGroup) match(ps *procStat, pidfile int32) bool {
+	if pg.Process != "" && ps.name != pg.Process {
+		return false
+	}
+
+	if pg.cmdline != nil && !pg.cmdline.MatchString(ps.cmdline) {
+		return false
+	}
+
+	if pg.Pidfile != "" && ps.pid != pidfile {
+		return false
+	}
+
+	if pg.Unit != "" && !inUnit(readProcessCgroup(ps.pid), pg.Unit) {
+		return false
+	}
+
+	return true
+}
+
+// inUnit checks if the cgroup file content contains the systemd unit.
+// Line format: "hierarchy-ID:controller-list:cgroup-path"
+func inUnit(cgroup string, unit string) bool {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		path := parts[2] + "/"
+		if strings.Contains(path, "/"+unit+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pi *processInfo) fields() []*field.Field {
+	return processFields
+}
+
+func (pi *processInfo) init() error {
+	if pi.Top < 0 {
+		return fmt.Errorf("invalid top value: %d", pi.Top)
+	}
+
+	if len(pi.Groups) == 0 && pi.Top == 0 {
+		return fmt.Errorf("groups or top is required")
+	}
+
+	for _, pg := range pi.Groups {
+		if err := pg.init(); err != nil {
+			return err
+		}
+	}
+
+	pi.state = make(map[procKey]*procStat)
+
+	return nil
+}
+
+// collect returns a row per matched process.
+func (pi *processInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if pi == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	procs, err := listProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("get processes: %w", err)
+	}
+
+	elapsed := float64(0)
+	if !pi.time.IsZero() {
+		elapsed = now.Sub(pi.time).Seconds()
+	}
+
+	// CPU usage is required for all processes to select the top
+	usage := make(map[procKey]float64, len(procs))
+	for _, ps := range procs {
+		key := procKey{ps.pid, ps.createTime}
+		if prev, ok := pi.state[key]; ok && elapsed > 0 {
+			usage[key] = math.Max(ps.cpuTime-prev.cpuTime, 0) / elapsed * 100
+		}
+	}
+
+	selected := make(map[procKey]string)
+	var order []*procStat
+
+	for _, pg := range pi.Groups {
+		pidfile := pg.readPidfile()
+		for _, ps := range procs {
+			key := procKey{ps.pid, ps.createTime}
+			if _, ok := selected[key]; ok {
+				continue
+			}
+
+			if pg.match(ps, pidfile) {
+				selected[key] = pg.Name
+				order = append(order, ps)
+			}
+		}
+	}
+
+	// CPU usage is unknown on the first tick
+	if pi.Top > 0 && elapsed > 0 {
+		top := make([]*procStat, len(procs))
+		copy(top, procs)
+		sort.SliceStable(top, func(i, j int) bool {
+			return usage[procKey{top[i].pid, top[i].createTime}] > usage[procKey{top[j].pid, top[j].createTime}]
+		})
+
+		for _, ps := range top[:min(pi.Top, len(top))] {
+			key := procKey{ps.pid, ps.createTime}
+			if _, ok := selected[key]; !ok {
+				selected[key] = "top"
+				order = append(order, ps)
+			}
+		}
+	}
+
+	rows := make([]map[string]any, 0, len(order))
+	state := make(map[procKey]*procStat, len(procs))
+
+	for _, ps := range order {
+		key := procKey{ps.pid, ps.createTime}
+		loadProcessDetails(ps)
+		ps.details = true
+
+		row := map[string]any{
+			"time":             data["time"],
+			"proc_group":       selected[key],
+			"proc_pid":         int64(ps.pid),
+			"proc_name":        ps.name,
+			"proc_cmdline":     ps.cmdline,
+			"proc_cpu":         math.Round(usage[key]*100) / 100,
+			"proc_rss":         int64(ps.rss),
+			"proc_fds":         int64(ps.fds),
+			"proc_threads":     int64(ps.threads),
+			"proc_read_bytes":  int64(0),
+			"proc_write_bytes": int64(0),
+		}
+
+		if prev, ok := pi.state[key]; ok && prev.details {
+			row["proc_read_bytes"] = counterDelta(ps.readBytes, prev.readBytes)
+			row["proc_write_bytes"] = counterDelta(ps.writeBytes, prev.writeBytes)
+		}
+
+		rows = append(rows, row)
+	}
+
+	// Keep CPU time of all processes to select the top on the next tick
+	for _, ps := range procs {
+		state[procKey{ps.pid, ps.createTime}] = ps
+	}
+
+	pi.state = state
+	pi.time = now
+
+	return rows, nil
+}
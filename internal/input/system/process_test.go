@@ -0,0 +1,132 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testProcess_Setup(t *testing.T) *[]*procStat {
+	stdList, stdDetails, stdCgroup := listProcesses, loadProcessDetails, readProcessCgroup
+	t.Cleanup(func() {
+		listProcesses, loadProcessDetails, readProcessCgroup = stdList, stdDetails, stdCgroup
+	})
+
+	var procs []*procStat
+	listProcesses = func() ([]*procStat, error) {
+		result := make([]*procStat, len(procs))
+		for i, ps := range procs {
+			clone := *ps
+			result[i] = &clone
+		}
+		return result, nil
+	}
+
+	loadProcessDetails = func(ps *procStat) {
+		ps.rss = uint64(ps.pid) * 1024
+		ps.threads = 4
+		ps.readBytes = uint64(ps.cpuTime * 1000)
+	}
+
+	readProcessCgroup = func(pid int32) string {
+		if pid == 300 {
+			return "0::/system.slice/postgresql.service\n"
+		}
+		return "0::/user.slice/user-1000.slice/session-1.scope\n"
+	}
+
+	return &procs
+}
+
+func TestProcessInfo_collect(t *testing.T) {
+	procs := testProcess_Setup(t)
+
+	pidfile := filepath.Join(t.TempDir(), "nginx.pid")
+	require.NoError(t, os.WriteFile(pidfile, []byte("100\n"), 0644))
+
+	*procs = []*procStat{
+		{pid: 100, name: "nginx", cmdline: "nginx: master process", createTime: 1, cpuTime: 10},
+		{pid: 101, name: "nginx", cmdline: "nginx: worker process", createTime: 1, cpuTime: 10},
+		{pid: 200, name: "java", cmdline: "java -jar /opt/app.jar", createTime: 1, cpuTime: 10},
+		{pid: 300, name: "postgres", cmdline: "postgres -D /var/lib/pg", createTime: 1, cpuTime: 10},
+		{pid: 400, name: "stress", cmdline: "stress --cpu 1", createTime: 1, cpuTime: 10},
+		{pid: 500, name: "sleep", cmdline: "sleep 100", createTime: 1, cpuTime: 10},
+	}
+
+	pi := &processInfo{
+		Groups: []*processGroup{
+			{Name: "nginx-master", Pidfile: pidfile},
+			{Name: "nginx", Process: "nginx"},
+			{Name: "app", Cmdline: `-jar /opt/app\.jar`},
+			{Name: "db", Unit: "postgresql.service"},
+		},
+		Top: 1,
+	}
+	require.NoError(t, pi.init())
+
+	data := map[string]any{"time": int64(1)}
+	rows, err := pi.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 4, "group processes should be reported")
+
+	groups := make(map[int64]string)
+	for _, row := range rows {
+		groups[row["proc_pid"].(int64)] = row["proc_group"].(string)
+		require.Equal(t, int64(1), row["time"])
+		require.Equal(t, float64(0), row["proc_cpu"], "first tick has no CPU usage")
+	}
+	require.Equal(t, map[int64]string{
+		100: "nginx-master",
+		101: "nginx",
+		200: "app",
+		300: "db",
+	}, groups)
+
+	// stress consumes the whole core, java a half
+	pi.time = time.Now().Add(-10 * time.Second)
+	(*procs)[2].cpuTime = 15
+	(*procs)[4].cpuTime = 20
+
+	rows, err = pi.collect(data)
+	require.NoError(t, err)
+	require.Len(t, rows, 5, "top process should be reported")
+
+	app := rows[2]
+	require.Equal(t, "app", app["proc_group"])
+	require.InDelta(t, 50, app["proc_cpu"], 1)
+	require.Equal(t, int64(200*1024), app["proc_rss"])
+	require.Equal(t, int64(4), app["proc_threads"])
+	require.Equal(t, int64(5000), app["proc_read_bytes"])
+
+	top := rows[4]
+	require.Equal(t, "top", top["proc_group"])
+	require.Equal(t, int64(400), top["proc_pid"])
+	require.InDelta(t, 100, top["proc_cpu"], 1)
+
+	// Restarted process with reused PID is a new process
+	(*procs)[2].createTime = 2
+
+	rows, err = pi.collect(data)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), rows[2]["proc_cpu"])
+	require.Equal(t, int64(0), rows[2]["proc_read_bytes"])
+}
+
+func TestProcessInfo_init(t *testing.T) {
+	require.Error(t, (&processInfo{}).init(), "groups or top is required")
+	require.Error(t, (&processInfo{Top: -1}).init(), "invalid top")
+	require.Error(t, (&processInfo{Groups: []*processGroup{{Process: "nginx"}}}).init(), "name is required")
+	require.Error(t, (&processInfo{Groups: []*processGroup{{Name: "empty"}}}).init(), "condition is required")
+	require.Error(t, (&processInfo{Groups: []*processGroup{{Name: "bad", Cmdline: "("}}}).init(), "invalid regex")
+	require.NoError(t, (&processInfo{Top: 5}).init())
+}
+
+func TestInUnit(t *testing.T) {
+	require.True(t, inUnit("0::/system.slice/nginx.service\n", "nginx.service"))
+	require.True(t, inUnit("12:pids:/system.slice/nginx.service/worker\n", "nginx.service"))
+	require.False(t, inUnit("0::/system.slice/nginx.service-helper\n", "nginx.service"))
+	require.False(t, inUnit("", "nginx.service"))
+}
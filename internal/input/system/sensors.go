@@ -0,0 +1,290 @@
+package system
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+var sensorsFields = []*field.Field{
+	{
+		Name:        "sensor_chip",
+		Type:        "string",
+		Description: "Sensor chip name, e.g. coretemp or thermal",
+	},
+	{
+		Name:        "sensor_name",
+		Type:        "string",
+		Description: "Sensor label, e.g. \"Package id 0\" or temp1",
+	},
+	{
+		Name:        "sensor_kind",
+		Type:        "string",
+		Description: "Sensor kind: temp, fan, or voltage",
+	},
+	{
+		Name:        "sensor_value",
+		Type:        "float",
+		Description: "Sensor value in degrees Celsius, RPM, or volts",
+	},
+	{
+		Name:        "sensor_critical",
+		Type:        "float",
+		Description: "Critical or maximum value reported by the sensor, 0 if unknown",
+	},
+}
+
+// Path to the device classes in sysfs.
+var sysClassPath = "/sys/class"
+
+// Hardware monitoring inputs by the file prefix: kind and divider to convert into units.
+var hwmonInputs = map[string]struct {
+	kind    string
+	divider float64
+}{
+	"temp": {"temp", 1000},
+	"fan":  {"fan", 1},
+	"in":   {"voltage", 1000},
+}
+
+var hwmonInputRe = regexp.MustCompile(`^(temp|fan|in)(\d+)_input$`)
+
+type sensorsInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Glob patterns of sensors to include in "chip/name" format.
+	// Default: all sensors
+	// Example: ["coretemp/*", "thermal/*"]
+	Include []string `yaml:"include,omitempty"`
+
+	// Glob patterns of sensors to exclude in "chip/name" format.
+	// Example: ["*/in*"]
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Sensor kinds to report: "temp", "fan", "voltage".
+	// Default: all kinds
+	Kinds []string `yaml:"kinds,omitempty"`
+}
+
+// sensor is a single sensor reading.
+type sensor struct {
+	chip     string
+	name     string
+	kind     string
+	value    float64
+	critical float64
+}
+
+func (si *sensorsInfo) fields() []*field.Field {
+	return sensorsFields
+}
+
+func (si *sensorsInfo) init() error {
+	for _, pattern := range slices.Concat(si.Include, si.Exclude) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid sensor pattern (%s): %w", pattern, err)
+		}
+	}
+
+	for _, kind := range si.Kinds {
+		switch kind {
+		case "temp", "fan", "voltage":
+		default:
+			return fmt.Errorf("unsupported sensor kind: %s", kind)
+		}
+	}
+
+	return nil
+}
+
+func (si *sensorsInfo) accept(s *sensor) bool {
+	if len(si.Kinds) > 0 && !slices.Contains(si.Kinds, s.kind) {
+		return false
+	}
+
+	name := s.chip + "/" + s.name
+
+	if len(si.Include) > 0 && !matchAny(si.Include, name) {
+		return false
+	}
+
+	return !matchAny(si.Exclude, name)
+}
+
+// collect returns a row per sensor.
+func (si *sensorsInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if si == nil {
+		return nil, nil
+	}
+
+	sensors := append(readHwmon(), readThermal()...)
+
+	rows := make([]map[string]any, 0, len(sensors))
+	for i := range sensors {
+		s := &sensors[i]
+		if !si.accept(s) {
+			continue
+		}
+
+		rows = append(rows, map[string]any{
+			"time":            data["time"],
+			"sensor_chip":     s.chip,
+			"sensor_name":     s.name,
+			"sensor_kind":     s.kind,
+			"sensor_value":    math.Round(s.value*1000) / 1000,
+			"sensor_critical": math.Round(s.critical*1000) / 1000,
+		})
+	}
+
+	return rows, nil
+}
+
+func readSysValue(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(string(data)), true
+}
+
+func readSysNumber(path string) (float64, bool) {
+	val, ok := readSysValue(path)
+	if !ok {
+		return 0, false
+	}
+
+	num, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
+
+// readHwmon reads temperatures, fan speeds, and voltages from /sys/class/hwmon.
+func readHwmon() []sensor {
+	dirs, _ := filepath.Glob(filepath.Join(sysClassPath, "hwmon", "hwmon*"))
+	sort.Strings(dirs)
+
+	var result []sensor
+
+	for _, dir := range dirs {
+		// Older drivers keep attributes in the device directory
+		chip, ok := readSysValue(filepath.Join(dir, "name"))
+		if !ok {
+			dir = filepath.Join(dir, "device")
+			if chip, ok = readSysValue(filepath.Join(dir, "name")); !ok {
+				continue
+			}
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			m := hwmonInputRe.FindStringSubmatch(entry.Name())
+			if m == nil {
+				continue
+			}
+
+			input := hwmonInputs[m[1]]
+			prefix := filepath.Join(dir, m[1]+m[2])
+
+			value, ok := readSysNumber(prefix + "_input")
+			if !ok {
+				// Sensor is not available
+				continue
+			}
+
+			s := sensor{
+				chip:  chip,
+				name:  m[1] + m[2],
+				kind:  input.kind,
+				value: value / input.divider,
+			}
+
+			if label, ok := readSysValue(prefix + "_label"); ok && label != "" {
+				s.name = label
+			}
+
+			if critical, ok := readSysNumber(prefix + "_crit"); ok {
+				s.critical = critical / input.divider
+			} else if critical, ok := readSysNumber(prefix + "_max"); ok {
+				s.critical = critical / input.divider
+			}
+
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// readThermal reads temperatures from /sys/class/thermal.
+func readThermal() []sensor {
+	dirs, _ := filepath.Glob(filepath.Join(sysClassPath, "thermal", "thermal_zone*"))
+	sort.Slice(dirs, func(i, j int) bool {
+		return zoneIndex(dirs[i]) < zoneIndex(dirs[j])
+	})
+
+	var result []sensor
+	names := make(map[string]int)
+
+	for _, dir := range dirs {
+		zone, ok := readSysValue(filepath.Join(dir, "type"))
+		if !ok {
+			continue
+		}
+
+		value, ok := readSysNumber(filepath.Join(dir, "temp"))
+		if !ok {
+			continue
+		}
+
+		// Zones of the same type are numbered, e.g. "acpitz", "acpitz_1"
+		name := zone
+		if n := names[zone]; n > 0 {
+			name = fmt.Sprintf("%s_%d", zone, n)
+		}
+		names[zone]++
+
+		s := sensor{
+			chip:  "thermal",
+			name:  name,
+			kind:  "temp",
+			value: value / 1000,
+		}
+
+		// Critical trip point
+		trips, _ := filepath.Glob(filepath.Join(dir, "trip_point_*_type"))
+		for _, trip := range trips {
+			if kind, _ := readSysValue(trip); kind == "critical" {
+				tempPath := strings.TrimSuffix(trip, "_type") + "_temp"
+				if critical, ok := readSysNumber(tempPath); ok {
+					s.critical = critical / 1000
+				}
+				break
+			}
+		}
+
+		result = append(result, s)
+	}
+
+	return result
+}
+
+func zoneIndex(path string) int {
+	idx, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "thermal_zone"))
+	return idx
+}
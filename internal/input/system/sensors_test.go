@@ -0,0 +1,119 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSensors_Write(t *testing.T, dir string, files map[string]string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0644))
+	}
+}
+
+func testSensors_Setup(t *testing.T) {
+	stdPath := sysClassPath
+	t.Cleanup(func() { sysClassPath = stdPath })
+
+	sysClassPath = t.TempDir()
+
+	testSensors_Write(t, filepath.Join(sysClassPath, "hwmon", "hwmon0"), map[string]string{
+		"name":        "coretemp",
+		"temp1_input": "65000",
+		"temp1_label": "Package id 0",
+		"temp1_crit":  "100000",
+		"temp2_input": "61500",
+		"temp2_label": "Core 0",
+		"temp2_max":   "90000",
+	})
+	testSensors_Write(t, filepath.Join(sysClassPath, "hwmon", "hwmon1"), map[string]string{
+		"name":       "nct6775",
+		"fan1_input": "1200",
+		"in0_input":  "1112",
+		"in0_label":  "Vcore",
+	})
+	// Older driver with attributes in the device directory
+	testSensors_Write(t, filepath.Join(sysClassPath, "hwmon", "hwmon2", "device"), map[string]string{
+		"name":        "it87",
+		"temp1_input": "40000",
+	})
+
+	testSensors_Write(t, filepath.Join(sysClassPath, "thermal", "thermal_zone0"), map[string]string{
+		"type":              "acpitz",
+		"temp":              "50000",
+		"trip_point_0_type": "critical",
+		"trip_point_0_temp": "105000",
+	})
+	testSensors_Write(t, filepath.Join(sysClassPath, "thermal", "thermal_zone1"), map[string]string{
+		"type": "acpitz",
+		"temp": "52000",
+	})
+}
+
+func TestSensorsInfo_collect(t *testing.T) {
+	testSensors_Setup(t)
+
+	si := &sensorsInfo{}
+	require.NoError(t, si.init())
+
+	rows, err := si.collect(map[string]any{"time": int64(1)})
+	require.NoError(t, err)
+
+	type reading struct {
+		kind     string
+		value    float64
+		critical float64
+	}
+
+	got := make(map[string]reading)
+	for _, row := range rows {
+		require.Equal(t, int64(1), row["time"])
+		name := row["sensor_chip"].(string) + "/" + row["sensor_name"].(string)
+		got[name] = reading{
+			kind:     row["sensor_kind"].(string),
+			value:    row["sensor_value"].(float64),
+			critical: row["sensor_critical"].(float64),
+		}
+	}
+
+	require.Equal(t, map[string]reading{
+		"coretemp/Package id 0": {"temp", 65, 100},
+		"coretemp/Core 0":       {"temp", 61.5, 90},
+		"nct6775/fan1":          {"fan", 1200, 0},
+		"nct6775/Vcore":         {"voltage", 1.112, 0},
+		"it87/temp1":            {"temp", 40, 0},
+		"thermal/acpitz":        {"temp", 50, 105},
+		"thermal/acpitz_1":      {"temp", 52, 0},
+	}, got)
+}
+
+func TestSensorsInfo_Filter(t *testing.T) {
+	testSensors_Setup(t)
+
+	si := &sensorsInfo{
+		Include: []string{"coretemp/*", "nct6775/*"},
+		Exclude: []string{"*/Core *"},
+		Kinds:   []string{"temp", "voltage"},
+	}
+	require.NoError(t, si.init())
+
+	rows, err := si.collect(map[string]any{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, row := range rows {
+		names = append(names, row["sensor_name"].(string))
+	}
+	require.Equal(t, []string{"Package id 0", "Vcore"}, names)
+}
+
+func TestSensorsInfo_init(t *testing.T) {
+	require.Error(t, (&sensorsInfo{Include: []string{"["}}).init(), "invalid pattern")
+	require.Error(t, (&sensorsInfo{Kinds: []string{"power"}}).init(), "unsupported kind")
+	require.NoError(t, (&sensorsInfo{}).init())
+}
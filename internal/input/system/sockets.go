@@ -0,0 +1,372 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+// TCP states in the order of their codes in /proc/net/tcp, starting from 0x01.
+var tcpStates = []string{
+	"established",
+	"syn_sent",
+	"syn_recv",
+	"fin_wait1",
+	"fin_wait2",
+	"time_wait",
+	"close",
+	"close_wait",
+	"last_ack",
+	"listen",
+	"closing",
+}
+
+const tcpStateListen = 0x0A
+
+// Counters from /proc/net/snmp and /proc/net/netstat reported as deltas.
+var socketsCounters = []struct {
+	name    string
+	section string
+	key     string
+	desc    string
+}{
+	{"sock_tcp_active_opens", "Tcp", "ActiveOpens", "Delta of outgoing TCP connections"},
+	{"sock_tcp_passive_opens", "Tcp", "PassiveOpens", "Delta of incoming TCP connections"},
+	{"sock_tcp_retrans", "Tcp", "RetransSegs", "Delta of retransmitted TCP segments"},
+	{"sock_tcp_resets", "Tcp", "OutRsts", "Delta of sent TCP resets"},
+	{"sock_tcp_listen_overflows", "TcpExt", "ListenOverflows", "Delta of accept queue overflows"},
+	{"sock_tcp_listen_drops", "TcpExt", "ListenDrops", "Delta of dropped incoming connections"},
+	{"sock_tcp_syncookies", "TcpExt", "SyncookiesSent", "Delta of sent SYN cookies"},
+	{"sock_udp_errors", "Udp", "InErrors", "Delta of UDP receive errors"},
+	{"sock_udp_rcvbuf_errors", "Udp", "RcvbufErrors", "Delta of UDP receive buffer errors"},
+}
+
+// Gauges from /proc/net/sockstat.
+var socketsGauges = []struct {
+	name    string
+	section string
+	key     string
+	desc    string
+}{
+	{"sock_tcp_inuse", "TCP", "inuse", "Number of TCP sockets in use"},
+	{"sock_tcp_orphan", "TCP", "orphan", "Number of orphaned TCP sockets"},
+	{"sock_tcp_tw", "TCP", "tw", "Number of TCP sockets in TIME_WAIT"},
+	{"sock_udp_inuse", "UDP", "inuse", "Number of UDP sockets in use"},
+}
+
+var socketsFields = func() []*field.Field {
+	var fields []*field.Field
+
+	for _, state := range tcpStates {
+		fields = append(fields, &field.Field{
+			Name:        "sock_tcp_" + state,
+			Type:        "int",
+			Description: fmt.Sprintf("Number of TCP connections in %s state", strings.ToUpper(state)),
+		})
+	}
+
+	for _, g := range socketsGauges {
+		fields = append(fields, &field.Field{
+			Name:        g.name,
+			Type:        "int",
+			Description: g.desc,
+		})
+	}
+
+	for _, c := range socketsCounters {
+		fields = append(fields, &field.Field{
+			Name:        c.name,
+			Type:        "int",
+			Description: c.desc,
+		})
+	}
+
+	return fields
+}()
+
+var socketsPortFields = []*field.Field{
+	{
+		Name:        "sock_port",
+		Type:        "int",
+		Description: "Listening TCP port",
+	},
+	{
+		Name:        "sock_port_connections",
+		Type:        "int",
+		Description: "Number of established connections to the port",
+	},
+	{
+		Name:        "sock_port_backlog",
+		Type:        "int",
+		Description: "Number of connections waiting in the accept queue",
+	},
+}
+
+// Path to the network statistics in procfs.
+var procNetPath = "/proc/net"
+
+type socketsInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Report a separate row per listening TCP port with the number of connections.
+	Ports bool `yaml:"ports,omitempty"`
+
+	ok       bool
+	counters map[string]uint64
+}
+
+func (si *socketsInfo) init() error {
+	return nil
+}
+
+func (si *socketsInfo) fields() []*field.Field {
+	fields := make([]*field.Field, 0)
+	fields = append(fields, socketsFields...)
+
+	if si.Ports {
+		fields = append(fields, socketsPortFields...)
+	}
+
+	return fields
+}
+
+// tcpSocket is a single line from /proc/net/tcp.
+type tcpSocket struct {
+	state   int
+	port    int
+	rxQueue int64
+}
+
+// collect adds the socket metrics to the main row and returns rows per port if enabled.
+func (si *socketsInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if si == nil {
+		return nil, nil
+	}
+
+	var sockets []tcpSocket
+	for _, name := range []string{"tcp", "tcp6"} {
+		items, err := readTcpSockets(filepath.Join(procNetPath, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// IPv6 is disabled
+				continue
+			}
+			return nil, fmt.Errorf("read %s sockets: %w", name, err)
+		}
+		sockets = append(sockets, items...)
+	}
+
+	states := make([]int64, len(tcpStates))
+	for _, s := range sockets {
+		if s.state >= 1 && s.state <= len(tcpStates) {
+			states[s.state-1]++
+		}
+	}
+
+	for i, state := range tcpStates {
+		data["sock_tcp_"+state] = states[i]
+	}
+
+	sockstat, err := readSectionValues(filepath.Join(procNetPath, "sockstat"))
+	if err != nil {
+		return nil, fmt.Errorf("read sockstat: %w", err)
+	}
+
+	for _, g := range socketsGauges {
+		data[g.name] = int64(sockstat[g.section][g.key])
+	}
+
+	snmp, err := readSectionTable(filepath.Join(procNetPath, "snmp"))
+	if err != nil {
+		return nil, fmt.Errorf("read snmp: %w", err)
+	}
+
+	netstat, err := readSectionTable(filepath.Join(procNetPath, "netstat"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read netstat: %w", err)
+	}
+
+	counters := make(map[string]uint64, len(socketsCounters))
+	for _, c := range socketsCounters {
+		val, ok := snmp[c.section][c.key]
+		if !ok {
+			val = netstat[c.section][c.key]
+		}
+		counters[c.name] = val
+
+		if si.ok {
+			data[c.name] = counterDelta(val, si.counters[c.name])
+		} else {
+			data[c.name] = int64(0)
+		}
+	}
+
+	si.counters = counters
+	si.ok = true
+
+	if !si.Ports {
+		return nil, nil
+	}
+
+	return portRows(sockets, data["time"]), nil
+}
+
+// portRows returns a row per listening port.
+func portRows(sockets []tcpSocket, timestamp any) []map[string]any {
+	backlog := make(map[int]int64)
+	for _, s := range sockets {
+		if s.state == tcpStateListen {
+			// Same port may listen on IPv4 and IPv6
+			backlog[s.port] += s.rxQueue
+		}
+	}
+
+	connections := make(map[int]int64, len(backlog))
+	for _, s := range sockets {
+		if _, ok := backlog[s.port]; ok && s.state == 1 {
+			connections[s.port]++
+		}
+	}
+
+	ports := make([]int, 0, len(backlog))
+	for port := range backlog {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	rows := make([]map[string]any, 0, len(ports))
+	for _, port := range ports {
+		rows = append(rows, map[string]any{
+			"time":                  timestamp,
+			"sock_port":             int64(port),
+			"sock_port_connections": connections[port],
+			"sock_port_backlog":     backlog[port],
+		})
+	}
+
+	return rows
+}
+
+// readTcpSockets parses /proc/net/tcp or /proc/net/tcp6.
+// Line format: "sl local_address rem_address st tx_queue:rx_queue ..."
+func readTcpSockets(path string) ([]tcpSocket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var result []tcpSocket
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		idx := strings.LastIndexByte(fields[1], ':')
+		if idx == -1 {
+			continue
+		}
+
+		port, err := strconv.ParseInt(fields[1][idx+1:], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		state, err := strconv.ParseInt(fields[3], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		var rxQueue int64
+		if _, rx, ok := strings.Cut(fields[4], ":"); ok {
+			rxQueue, _ = strconv.ParseInt(rx, 16, 64)
+		}
+
+		result = append(result, tcpSocket{
+			state:   int(state),
+			port:    int(port),
+			rxQueue: rxQueue,
+		})
+	}
+
+	return result, scanner.Err()
+}
+
+// readSectionValues parses lines with key-value pairs, e.g. "TCP: inuse 5 orphan 0 tw 2".
+func readSectionValues(path string) (map[string]map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]uint64)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		section, values, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(values)
+		items := make(map[string]uint64, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if val, err := strconv.ParseUint(fields[i+1], 10, 64); err == nil {
+				items[fields[i]] = val
+			}
+		}
+		result[section] = items
+	}
+
+	return result, nil
+}
+
+// readSectionTable parses pairs of header and value lines, e.g.
+// "Tcp: RtoAlgorithm RtoMin" followed by "Tcp: 1 200".
+func readSectionTable(path string) (map[string]map[string]uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]uint64)
+	lines := strings.Split(string(data), "\n")
+
+	for i := 0; i+1 < len(lines); i += 2 {
+		section, header, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			continue
+		}
+
+		_, values, ok := strings.Cut(lines[i+1], ":")
+		if !ok {
+			continue
+		}
+
+		keys := strings.Fields(header)
+		vals := strings.Fields(values)
+
+		items := make(map[string]uint64, len(keys))
+		for j := 0; j < len(keys) && j < len(vals); j++ {
+			// Some values are negative, e.g. Tcp MaxConn is -1
+			if val, err := strconv.ParseInt(vals[j], 10, 64); err == nil && val >= 0 {
+				items[keys[j]] = uint64(val)
+			} else if val, err := strconv.ParseUint(vals[j], 10, 64); err == nil {
+				items[keys[j]] = val
+			}
+		}
+		result[section] = items
+	}
+
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSockets_Tcp = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0050 00000000:0000 0A 00000000:00000003 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   112        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0A00000A:0050 0B00000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 20 4 30 10 -1
+   3: 0A00000A:0050 0C00000A:C351 01 00000000:00000000 00:00000000 00000000     0        0 1004 1 0000000000000000 20 4 30 10 -1
+   4: 0A00000A:0050 0D00000A:C352 06 00000000:00000000 03:00000100 00000000     0        0 0 3 0000000000000000
+   5: 0A00000A:D000 0E00000A:1538 01 00000000:00000000 00:00000000 00000000     0        0 1005 1 0000000000000000 20 4 30 10 -1
+`
+
+const testSockets_Tcp6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000001 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0
+   1: 0000000000000000FFFF00000A00000A:0050 0000000000000000FFFF00000F00000A:C353 03 00000000:00000000 00:00000000 00000000     0        0 0 1 0000000000000000 100 0 0 10 0
+`
+
+const testSockets_Sockstat = `sockets: used 150
+TCP: inuse 6 orphan 1 tw 1 alloc 8 mem 2
+UDP: inuse 3 mem 1
+UDPLITE: inuse 0
+RAW: inuse 0
+FRAG: inuse 0 memory 0
+`
+
+func testSockets_Snmp(activeOpens int, retrans int, udpErrors int) string {
+	return "Ip: Forwarding DefaultTTL\n" +
+		"Ip: 1 64\n" +
+		"Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens PassiveOpens RetransSegs OutRsts\n" +
+		fmt.Sprintf("Tcp: 1 200 120000 -1 %d 50 %d 7\n", activeOpens, retrans) +
+		"Udp: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors\n" +
+		fmt.Sprintf("Udp: 1000 1 %d 900 0\n", udpErrors)
+}
+
+func testSockets_Netstat(overflows int) string {
+	return "TcpExt: SyncookiesSent ListenOverflows ListenDrops\n" +
+		fmt.Sprintf("TcpExt: 0 %d %d\n", overflows, overflows) +
+		"IpExt: InNoRoutes\n" +
+		"IpExt: 0\n"
+}
+
+func testSockets_Setup(t *testing.T) string {
+	stdPath := procNetPath
+	t.Cleanup(func() { procNetPath = stdPath })
+
+	procNetPath = t.TempDir()
+
+	files := map[string]string{
+		"tcp":      testSockets_Tcp,
+		"tcp6":     testSockets_Tcp6,
+		"sockstat": testSockets_Sockstat,
+		"snmp":     testSockets_Snmp(1, 2, 0),
+		"netstat":  testSockets_Netstat(0),
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(procNetPath, name), []byte(content), 0644))
+	}
+
+	return procNetPath
+}
+
+func TestSocketsInfo_collect(t *testing.T) {
+	dir := testSockets_Setup(t)
+
+	si := &socketsInfo{Ports: true}
+
+	data := map[string]any{"time": int64(1)}
+	rows, err := si.collect(data)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), data["sock_tcp_established"])
+	require.Equal(t, int64(3), data["sock_tcp_listen"])
+	require.Equal(t, int64(1), data["sock_tcp_time_wait"])
+	require.Equal(t, int64(1), data["sock_tcp_syn_recv"])
+	require.Equal(t, int64(0), data["sock_tcp_close_wait"])
+	require.Equal(t, int64(6), data["sock_tcp_inuse"])
+	require.Equal(t, int64(1), data["sock_tcp_orphan"])
+	require.Equal(t, int64(3), data["sock_udp_inuse"])
+	require.Equal(t, int64(0), data["sock_tcp_retrans"], "first tick has no deltas")
+
+	require.Equal(t, []map[string]any{
+		{
+			"time":                  int64(1),
+			"sock_port":             int64(80),
+			"sock_port_connections": int64(2),
+			"sock_port_backlog":     int64(4),
+		},
+		{
+			"time":                  int64(1),
+			"sock_port":             int64(5432),
+			"sock_port_connections": int64(0),
+			"sock_port_backlog":     int64(0),
+		},
+	}, rows)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "snmp"), []byte(testSockets_Snmp(4, 9, 3)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "netstat"), []byte(testSockets_Netstat(5)), 0644))
+
+	data = map[string]any{"time": int64(2)}
+	_, err = si.collect(data)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), data["sock_tcp_active_opens"])
+	require.Equal(t, int64(0), data["sock_tcp_passive_opens"])
+	require.Equal(t, int64(7), data["sock_tcp_retrans"])
+	require.Equal(t, int64(5), data["sock_tcp_listen_overflows"])
+	require.Equal(t, int64(5), data["sock_tcp_listen_drops"])
+	require.Equal(t, int64(3), data["sock_udp_errors"])
+
+	// Every field is reported
+	for _, f := range si.fields()[:len(socketsFields)] {
+		require.Contains(t, data, f.Name)
+	}
+}
+
+func TestSocketsInfo_NoIPv6(t *testing.T) {
+	dir := testSockets_Setup(t)
+	require.NoError(t, os.Remove(filepath.Join(dir, "tcp6")))
+
+	si := &socketsInfo{}
+
+	data := make(map[string]any)
+	rows, err := si.collect(data)
+	require.NoError(t, err)
+	require.Nil(t, rows, "ports are disabled")
+	require.Equal(t, int64(2), data["sock_tcp_listen"])
+}
@@ -0,0 +1,239 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+)
+
+var unitsFields = []*field.Field{
+	{
+		Name:        "unit_name",
+		Type:        "string",
+		Description: "Systemd unit name",
+	},
+	{
+		Name:        "unit_active_state",
+		Type:        "string",
+		Description: "Unit active state, e.g. active, failed",
+	},
+	{
+		Name:        "unit_sub_state",
+		Type:        "string",
+		Description: "Unit sub state, e.g. running, exited",
+	},
+	{
+		Name:        "unit_prev_state",
+		Type:        "string",
+		Description: "Previous state in \"active/sub\" format, empty on the first record",
+	},
+	{
+		Name:        "unit_restarts",
+		Type:        "int",
+		Description: "Number of automatic restarts",
+	},
+	{
+		Name:        "unit_main_pid",
+		Type:        "int",
+		Description: "Main process ID",
+	},
+	{
+		Name:        "unit_memory",
+		Type:        "int",
+		Description: "Memory usage in bytes, 0 if accounting is disabled",
+	},
+	{
+		Name:        "unit_cpu_time",
+		Type:        "int",
+		Description: "Total CPU time in milliseconds, 0 if accounting is disabled",
+	},
+}
+
+var unitsProperties = []string{
+	"Id",
+	"ActiveState",
+	"SubState",
+	"NRestarts",
+	"MainPID",
+	"MemoryCurrent",
+	"CPUUsageNSec",
+}
+
+// showUnits returns properties of the units in the "systemctl show" format.
+var showUnits = func(ctx context.Context, names []string) ([]byte, error) {
+	args := []string{"show", "--property=" + strings.Join(unitsProperties, ","), "--"}
+	args = append(args, names...)
+
+	cmd := exec.CommandContext(ctx, "systemctl", args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if text := strings.TrimSpace(stderr.String()); text != "" {
+			return nil, fmt.Errorf("%w: %s", err, text)
+		}
+		return nil, err
+	}
+
+	return output, nil
+}
+
+type unitsInfo struct {
+	collectorConfig `yaml:",inline"`
+
+	// Systemd units to monitor.
+	// Example: ["nginx.service", "postgresql.service"]
+	Names []string `yaml:"names"`
+
+	state map[string]*unitState
+}
+
+// unitState is the state of the unit reported by systemctl.
+type unitState struct {
+	name        string
+	activeState string
+	subState    string
+	restarts    int64
+	mainPID     int64
+	memory      int64
+	cpuTime     int64
+}
+
+func (us *unitState) String() string {
+	return us.activeState + "/" + us.subState
+}
+
+func (ui *unitsInfo) fields() []*field.Field {
+	return unitsFields
+}
+
+func (ui *unitsInfo) init() error {
+	if len(ui.Names) == 0 {
+		return fmt.Errorf("names is required")
+	}
+
+	for _, name := range ui.Names {
+		if name == "" || strings.HasPrefix(name, "-") {
+			return fmt.Errorf("invalid unit name: %q", name)
+		}
+	}
+
+	ui.state = make(map[string]*unitState)
+
+	return nil
+}
+
+// collect returns a row per unit which state has changed since the previous tick.
+// The first tick reports all units.
+func (ui *unitsInfo) collect(data map[string]any) ([]map[string]any, error) {
+	if ui == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := showUnits(ctx, ui.Names)
+	if err != nil {
+		return nil, fmt.Errorf("get units state: %w", err)
+	}
+
+	var rows []map[string]any
+
+	for _, current := range parseUnits(output) {
+		prev, ok := ui.state[current.name]
+		ui.state[current.name] = current
+
+		if ok &&
+			prev.activeState == current.activeState &&
+			prev.subState == current.subState &&
+			prev.restarts == current.restarts {
+			continue
+		}
+
+		row := map[string]any{
+			"time":              data["time"],
+			"unit_name":         current.name,
+			"unit_active_state": current.activeState,
+			"unit_sub_state":    current.subState,
+			"unit_prev_state":   "",
+			"unit_restarts":     current.restarts,
+			"unit_main_pid":     current.mainPID,
+			"unit_memory":       current.memory,
+			"unit_cpu_time":     current.cpuTime,
+		}
+
+		if ok {
+			row["unit_prev_state"] = prev.String()
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseUnits parses the "systemctl show" output.
+// Properties of each unit are separated by the empty line.
+func parseUnits(output []byte) []*unitState {
+	var result []*unitState
+	var current *unitState
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			current = nil
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		if current == nil {
+			current = &unitState{}
+			result = append(result, current)
+		}
+
+		switch key {
+		case "Id":
+			current.name = value
+		case "ActiveState":
+			current.activeState = value
+		case "SubState":
+			current.subState = value
+		case "NRestarts":
+			current.restarts = parseUnitNumber(value)
+		case "MainPID":
+			current.mainPID = parseUnitNumber(value)
+		case "MemoryCurrent":
+			current.memory = parseUnitNumber(value)
+		case "CPUUsageNSec":
+			current.cpuTime = parseUnitNumber(value) / int64(time.Millisecond)
+		}
+	}
+
+	return result
+}
+
+// parseUnitNumber returns 0 for "[not set]" and the maximum value used by systemd for unknown.
+func parseUnitNumber(value string) int64 {
+	num, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || num > math.MaxInt64 {
+		return 0
+	}
+
+	return int64(num)
+}
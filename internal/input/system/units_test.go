@@ -0,0 +1,110 @@
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testUnits_Setup(t *testing.T) *string {
+	stdShow := showUnits
+	t.Cleanup(func() { showUnits = stdShow })
+
+	var output string
+	showUnits = func(ctx context.Context, names []string) ([]byte, error) {
+		return []byte(output), nil
+	}
+
+	return &output
+}
+
+const testUnits_Running = `Id=nginx.service
+ActiveState=active
+SubState=running
+NRestarts=0
+MainPID=1234
+MemoryCurrent=52428800
+CPUUsageNSec=1500000000
+
+Id=backup.service
+ActiveState=inactive
+SubState=dead
+NRestarts=0
+MainPID=0
+MemoryCurrent=[not set]
+CPUUsageNSec=18446744073709551615
+`
+
+func TestUnitsInfo_collect(t *testing.T) {
+	output := testUnits_Setup(t)
+
+	ui := &unitsInfo{Names: []string{"nginx.service", "backup.service"}}
+	require.NoError(t, ui.init())
+
+	*output = testUnits_Running
+	rows, err := ui.collect(map[string]any{"time": int64(1)})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{
+			"time":              int64(1),
+			"unit_name":         "nginx.service",
+			"unit_active_state": "active",
+			"unit_sub_state":    "running",
+			"unit_prev_state":   "",
+			"unit_restarts":     int64(0),
+			"unit_main_pid":     int64(1234),
+			"unit_memory":       int64(52428800),
+			"unit_cpu_time":     int64(1500),
+		},
+		{
+			"time":              int64(1),
+			"unit_name":         "backup.service",
+			"unit_active_state": "inactive",
+			"unit_sub_state":    "dead",
+			"unit_prev_state":   "",
+			"unit_restarts":     int64(0),
+			"unit_main_pid":     int64(0),
+			"unit_memory":       int64(0),
+			"unit_cpu_time":     int64(0),
+		},
+	}, rows, "first tick should report all units")
+
+	// Nothing has changed except accounting
+	*output = testUnits_Running
+	rows, err = ui.collect(map[string]any{"time": int64(2)})
+	require.NoError(t, err)
+	require.Empty(t, rows, "unchanged units should not be reported")
+
+	// nginx has been restarted by systemd
+	*output = `Id=nginx.service
+ActiveState=activating
+SubState=auto-restart
+NRestarts=1
+MainPID=0
+MemoryCurrent=[not set]
+CPUUsageNSec=[not set]
+
+Id=backup.service
+ActiveState=inactive
+SubState=dead
+NRestarts=0
+MainPID=0
+MemoryCurrent=[not set]
+CPUUsageNSec=[not set]
+`
+	rows, err = ui.collect(map[string]any{"time": int64(3)})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "nginx.service", rows[0]["unit_name"])
+	require.Equal(t, "activating", rows[0]["unit_active_state"])
+	require.Equal(t, "auto-restart", rows[0]["unit_sub_state"])
+	require.Equal(t, "active/running", rows[0]["unit_prev_state"])
+	require.Equal(t, int64(1), rows[0]["unit_restarts"])
+}
+
+func TestUnitsInfo_init(t *testing.T) {
+	require.Error(t, (&unitsInfo{}).init(), "names is required")
+	require.Error(t, (&unitsInfo{Names: []string{"--all"}}).init(), "invalid unit name")
+	require.NoError(t, (&unitsInfo{Names: []string{"nginx.service"}}).init())
+}
@@ -3,6 +3,8 @@ package system
 import (
 	"fmt"
 	"log"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/fugo-app/fugo/internal/field"
@@ -15,15 +17,24 @@ type SystemWatcher struct {
 	// Interval to check the system status. Default is 60s
 	Interval string `yaml:"interval,omitempty"`
 
-	Disk *diskInfo `yaml:"disk,omitempty"`
+	Cpu  cpuInfo   `yaml:"cpu,omitempty"`
+	Mem  memInfo   `yaml:"mem,omitempty"`
+	Disk *diskList `yaml:"disk,omitempty"`
 	Net  *netInfo  `yaml:"net,omitempty"`
 
+	Process *processInfo `yaml:"process,omitempty"`
+	Cgroup  *cgroupInfo  `yaml:"cgroup,omitempty"`
+	Sockets *socketsInfo `yaml:"sockets,omitempty"`
+	Sensors *sensorsInfo `yaml:"sensors,omitempty"`
+	Units   *unitsInfo   `yaml:"units,omitempty"`
+
 	interval  time.Duration
 	processor input.Processor
-
-	cpu cpuInfo
+	groups    []*collectorGroup
+	selected  map[string]struct{}
 
 	stop chan struct{}
+	wg   sync.WaitGroup
 }
 
 var baseFields = []*field.Field{
@@ -38,24 +49,125 @@ var baseFields = []*field.Field{
 	},
 }
 
-func (sw *SystemWatcher) Fields() []*field.Field {
-	fields := make([]*field.Field, 0)
+// namedCollector is a collector with the name of the configuration section.
+type namedCollector struct {
+	name string
+	collector
+}
 
-	fields = append(fields, baseFields...)
-	fields = append(fields, cpuFields...)
-	fields = append(fields, memFields...)
+// collectors returns the enabled collectors in the reporting order.
+func (sw *SystemWatcher) collectors() []namedCollector {
+	list := []namedCollector{
+		{"cpu", &sw.Cpu},
+		{"mem", &sw.Mem},
+	}
 
+	// Optional collectors are checked one by one to avoid typed nil interfaces
 	if sw.Disk != nil {
-		fields = append(fields, diskFields...)
+		list = append(list, namedCollector{"disk", sw.Disk})
 	}
-
 	if sw.Net != nil {
-		fields = append(fields, netFields...)
+		list = append(list, namedCollector{"net", sw.Net})
+	}
+	if sw.Process != nil {
+		list = append(list, namedCollector{"process", sw.Process})
+	}
+	if sw.Cgroup != nil {
+		list = append(list, namedCollector{"cgroup", sw.Cgroup})
+	}
+	if sw.Sockets != nil {
+		list = append(list, namedCollector{"sockets", sw.Sockets})
+	}
+	if sw.Sensors != nil {
+		list = append(list, namedCollector{"sensors", sw.Sensors})
+	}
+	if sw.Units != nil {
+		list = append(list, namedCollector{"units", sw.Units})
+	}
+
+	return slices.DeleteFunc(list, func(c namedCollector) bool {
+		return !c.config().isEnabled()
+	})
+}
+
+// Fields returns all fields reported by the enabled collectors.
+func (sw *SystemWatcher) Fields() []*field.Field {
+	fields := make([]*field.Field, 0)
+	fields = append(fields, baseFields...)
+
+	for _, c := range sw.collectors() {
+		fields = append(fields, c.fields()...)
 	}
 
 	return fields
 }
 
+// SelectFields returns copies of the system fields listed by the user.
+// If the list is empty all fields of the enabled collectors are returned.
+// Only description and index of the system fields could be overridden by the user,
+// other fields in the list are custom fields and used as is.
+// Metrics not in the list are removed from the records.
+func (sw *SystemWatcher) SelectFields(user []*field.Field) ([]*field.Field, error) {
+	available := sw.Fields()
+
+	if len(user) == 0 {
+		result := make([]*field.Field, len(available))
+		for i, f := range available {
+			result[i] = cloneField(f)
+		}
+		sw.selected = nil
+		return result, nil
+	}
+
+	byName := make(map[string]*field.Field, len(available))
+	for _, f := range available {
+		byName[f.Name] = f
+	}
+
+	result := make([]*field.Field, 0, len(user)+1)
+	selected := make(map[string]struct{}, len(user)+1)
+
+	// Time field is always required
+	if !slices.ContainsFunc(user, func(f *field.Field) bool { return f.Name == "time" }) {
+		result = append(result, cloneField(baseFields[0]))
+		selected["time"] = struct{}{}
+	}
+
+	for _, u := range user {
+		if _, ok := selected[u.Name]; ok {
+			return nil, fmt.Errorf("duplicate system field: %s", u.Name)
+		}
+		selected[u.Name] = struct{}{}
+
+		f, ok := byName[u.Name]
+		if !ok {
+			result = append(result, cloneField(u))
+			continue
+		}
+
+		f = cloneField(f)
+		if u.Description != "" {
+			f.Description = u.Description
+		}
+		f.Index = f.Index || u.Index
+
+		result = append(result, f)
+	}
+
+	sw.selected = selected
+
+	return result, nil
+}
+
+// cloneField returns a copy of the field with description and index,
+// which are not copied by Field.Clone.
+func cloneField(f *field.Field) *field.Field {
+	c := f.Clone()
+	c.Description = f.Description
+	c.Index = f.Index
+	return c
+}
+
 func (sw *SystemWatcher) Init(processor input.Processor) error {
 	sw.interval = 60 * time.Second // Default to 60 seconds
 	if sw.Interval != "" {
@@ -67,83 +179,127 @@ func (sw *SystemWatcher) Init(processor input.Processor) error {
 	}
 	sw.processor = processor
 
-	if sw.Disk != nil {
-		if err := sw.Disk.init(); err != nil {
-			return fmt.Errorf("init disk info: %w", err)
+	sw.groups = nil
+
+	for _, c := range sw.collectors() {
+		if err := c.config().initConfig(c.name, sw.interval); err != nil {
+			return fmt.Errorf("init %s config: %w", c.name, err)
 		}
-	}
 
-	if sw.Net != nil {
-		if err := sw.Net.init(); err != nil {
-			return fmt.Errorf("init net info: %w", err)
+		if err := c.init(); err != nil {
+			return fmt.Errorf("init %s info: %w", c.name, err)
 		}
+
+		sw.addCollector(c.collector)
 	}
 
 	return nil
 }
 
+// addCollector adds the collector to the group with the same interval.
+func (sw *SystemWatcher) addCollector(c collector) {
+	interval := c.config().interval
+
+	for _, g := range sw.groups {
+		if g.interval == interval {
+			g.collectors = append(g.collectors, c)
+			return
+		}
+	}
+
+	sw.groups = append(sw.groups, &collectorGroup{
+		interval:   interval,
+		collectors: []collector{c},
+	})
+}
+
 func (sw *SystemWatcher) Start() {
 	sw.stop = make(chan struct{})
-	go sw.watch()
+
+	for _, g := range sw.groups {
+		sw.wg.Add(1)
+		go sw.watch(g)
+	}
 }
 
 func (sw *SystemWatcher) Stop() {
 	if sw.stop != nil {
 		close(sw.stop)
+		sw.wg.Wait()
+		sw.stop = nil
 	}
 }
 
-func (sw *SystemWatcher) watch() {
-	sw.collect()
+func (sw *SystemWatcher) watch(g *collectorGroup) {
+	defer sw.wg.Done()
+
+	sw.collect(g)
 
-	ticker := time.NewTicker(sw.interval)
+	ticker := time.NewTicker(g.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			sw.collect()
+			sw.collect(g)
 		case <-sw.stop:
 			return
 		}
 	}
 }
 
-func (sw *SystemWatcher) collect() {
-	if err := sw._collect(); err != nil {
-		log.Printf("Error on collecting system status: %v\n", err)
-	}
-}
-
-func (sw *SystemWatcher) _collect() error {
+// collect writes the main row with metrics of all collectors in the group
+// and additional rows returned by collectors.
+// Failed collector is logged and skipped, other metrics are still reported.
+func (sw *SystemWatcher) collect(g *collectorGroup) {
 	data := make(map[string]any)
 
 	data["time"] = time.Now().UnixMilli()
 
 	// Uptime
-	uptime, err := host.Uptime()
-	if err != nil {
-		return fmt.Errorf("get host uptime: %w", err)
+	if uptime, err := host.Uptime(); err != nil {
+		log.Printf("Error on collecting system uptime: %v\n", err)
+	} else {
+		data["uptime"] = int64(uptime)
 	}
-	data["uptime"] = int64(uptime)
 
-	if err := sw.cpu.collect(data); err != nil {
-		return err
-	}
+	base := len(data)
 
-	if err := collectMem(data); err != nil {
-		return err
+	var rows []map[string]any
+
+	for _, c := range g.collectors {
+		items, err := c.collect(data)
+		if err != nil {
+			log.Printf("Error on collecting %s status: %v\n", c.config().name, err)
+			continue
+		}
+		rows = append(rows, items...)
 	}
 
-	if err := sw.Disk.collect(data); err != nil {
-		return err
+	// Skip the main row if collectors report metrics only in separate rows
+	if len(data) > base {
+		sw.write(data)
 	}
 
-	if err := sw.Net.collect(data); err != nil {
-		return err
+	for _, row := range rows {
+		sw.write(row)
 	}
+}
 
-	sw.processor.Write(data)
+// write removes metrics not selected by the user and writes the row.
+func (sw *SystemWatcher) write(row map[string]any) {
+	if sw.selected != nil {
+		for key := range row {
+			if _, ok := sw.selected[key]; !ok {
+				delete(row, key)
+			}
+		}
 
-	return nil
+		// Nothing to report besides the time
+		if len(row) <= 1 {
+			return
+		}
+	}
+
+	sw.processor.Write(row)
 }
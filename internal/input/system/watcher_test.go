@@ -0,0 +1,176 @@
+package system
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fugo-app/fugo/internal/field"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testProcessor struct {
+	rows []map[string]any
+}
+
+func (tp *testProcessor) Serialize(data map[string]string) (map[string]any, error) {
+	return nil, nil
+}
+
+func (tp *testProcessor) Write(data map[string]any) {
+	tp.rows = append(tp.rows, data)
+}
+
+func (tp *testProcessor) Reject(source string, offset int64, line string, reason error) {}
+
+// testCollector adds static metrics to the main row or fails.
+type testCollector struct {
+	collectorConfig
+
+	data map[string]any
+	err  error
+}
+
+func (tc *testCollector) init() error {
+	return nil
+}
+
+func (tc *testCollector) fields() []*field.Field {
+	return nil
+}
+
+func (tc *testCollector) collect(data map[string]any) ([]map[string]any, error) {
+	if tc.err != nil {
+		return nil, tc.err
+	}
+
+	for key, val := range tc.data {
+		data[key] = val
+	}
+
+	return nil, nil
+}
+
+func testWatcher_FieldNames(fields []*field.Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func TestSystemWatcher_Init(t *testing.T) {
+	testUnits_Setup(t)
+
+	config := `
+interval: 10s
+mem:
+  enabled: false
+sockets: {}
+units:
+  interval: 1m
+  names: [nginx.service]
+net:
+  enabled: false
+`
+
+	var sw SystemWatcher
+	require.NoError(t, yaml.Unmarshal([]byte(config), &sw))
+	require.NoError(t, sw.Init(&testProcessor{}))
+
+	require.Len(t, sw.groups, 2)
+	require.Equal(t, 10*time.Second, sw.groups[0].interval)
+	require.Equal(t, []collector{&sw.Cpu, sw.Sockets}, sw.groups[0].collectors)
+	require.Equal(t, time.Minute, sw.groups[1].interval)
+	require.Equal(t, []collector{sw.Units}, sw.groups[1].collectors)
+
+	names := testWatcher_FieldNames(sw.Fields())
+	require.Contains(t, names, "cpu_usage")
+	require.Contains(t, names, "sock_tcp_established")
+	require.Contains(t, names, "unit_name")
+	require.NotContains(t, names, "mem_usage")
+	require.NotContains(t, names, "net_if")
+}
+
+func TestSystemWatcher_InitError(t *testing.T) {
+	var sw SystemWatcher
+	require.NoError(t, yaml.Unmarshal([]byte("cpu:\n  interval: 0s\n"), &sw))
+	require.ErrorContains(t, sw.Init(&testProcessor{}), "init cpu config")
+}
+
+func TestSystemWatcher_SelectFields(t *testing.T) {
+	var sw SystemWatcher
+
+	_, err := sw.SelectFields([]*field.Field{{Name: "la_1"}, {Name: "la_1"}})
+	require.ErrorContains(t, err, "duplicate system field: la_1")
+
+	// Custom fields are used as is
+	fields, err := sw.SelectFields([]*field.Field{
+		{Name: "host", Template: "web-1"},
+		{Name: "cpu_usage"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"time", "host", "cpu_usage"}, testWatcher_FieldNames(fields))
+	require.Equal(t, "web-1", fields[1].Template)
+	require.Contains(t, sw.selected, "host")
+
+	fields, err = sw.SelectFields([]*field.Field{
+		{Name: "cpu_usage", Index: true},
+		{Name: "la_1", Description: "Load"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"time", "cpu_usage", "la_1"}, testWatcher_FieldNames(fields))
+	require.Equal(t, "float", fields[1].Type)
+	require.True(t, fields[1].Index)
+	require.Equal(t, "CPU usage percentage", fields[1].Description, "system description should be kept")
+	require.Equal(t, "Load", fields[2].Description)
+	require.False(t, cpuFields[0].Index, "system fields should not be modified")
+
+	fields, err = sw.SelectFields(nil)
+	require.NoError(t, err)
+	require.Equal(t, testWatcher_FieldNames(sw.Fields()), testWatcher_FieldNames(fields))
+	require.Nil(t, sw.selected)
+}
+
+func TestSystemWatcher_collect(t *testing.T) {
+	processor := &testProcessor{}
+	sw := SystemWatcher{
+		processor: processor,
+	}
+
+	_, err := sw.SelectFields([]*field.Field{
+		{Name: "time"},
+		{Name: "cpu_usage"},
+		{Name: "mem_usage"},
+	})
+	require.NoError(t, err)
+
+	g := &collectorGroup{
+		interval: time.Minute,
+		collectors: []collector{
+			&testCollector{data: map[string]any{"cpu_usage": 10.0, "la_1": 1.0}},
+			&testCollector{
+				collectorConfig: collectorConfig{name: "failed"},
+				err:             fmt.Errorf("failed"),
+			},
+			&testCollector{data: map[string]any{"mem_usage": 20.0}},
+		},
+	}
+
+	// Failed collector should not drop metrics of other collectors
+	sw.collect(g)
+	require.Len(t, processor.rows, 1)
+	require.Equal(t, 10.0, processor.rows[0]["cpu_usage"])
+	require.Equal(t, 20.0, processor.rows[0]["mem_usage"])
+	require.NotContains(t, processor.rows[0], "la_1")
+	require.NotContains(t, processor.rows[0], "uptime")
+
+	// Row without selected metrics is skipped
+	processor.rows = nil
+	g.collectors = []collector{
+		&testCollector{data: map[string]any{"la_1": 1.0}},
+	}
+	sw.collect(g)
+	require.Empty(t, processor.rows)
+}
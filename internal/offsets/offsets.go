@@ -0,0 +1,181 @@
+package offsets
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fugo-app/fugo/pkg/debounce"
+)
+
+// Store keeps reading positions of the inputs and saves them to the file.
+// Offsets are numeric positions, e.g. file offsets.
+// Cursors are string positions, e.g. journal cursors or kernel log sequence numbers.
+type Store struct {
+	path string
+
+	mutex   sync.Mutex
+	offsets map[string]int64
+	cursors map[string]string
+
+	debounce *debounce.Debounce
+}
+
+var globalStore *Store
+
+// Open loads the positions from the file and makes the store available for the inputs.
+// Positions are kept in memory only if the path is empty.
+func Open(path string) error {
+	s := &Store{
+		path:    path,
+		offsets: make(map[string]int64),
+		cursors: make(map[string]string),
+	}
+
+	if path != "" {
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create offsets directory: %w", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("read offsets file: %w", err)
+			}
+		} else {
+			if err := s.load(data); err != nil {
+				return fmt.Errorf("unmarshal offsets: %w", err)
+			}
+		}
+	}
+
+	s.debounce = debounce.NewDebounce(s.save, time.Second, false)
+	s.debounce.Start()
+
+	globalStore = s
+
+	return nil
+}
+
+// Close saves the positions to the file.
+func Close() {
+	if globalStore == nil {
+		return
+	}
+
+	globalStore.debounce.Stop()
+	globalStore.save()
+}
+
+// load parses the offsets file.
+// Numeric values are offsets, string values are cursors.
+func (s *Store) load(data []byte) error {
+	var state map[string]any
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	for key, val := range state {
+		switch v := val.(type) {
+		case int:
+			s.offsets[key] = int64(v)
+		case string:
+			s.cursors[key] = v
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) prepare() []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := make(map[string]any, len(s.offsets)+len(s.cursors))
+	for key, val := range s.offsets {
+		state[key] = val
+	}
+	for key, val := range s.cursors {
+		state[key] = val
+	}
+
+	out, err := yaml.Marshal(&state)
+	if err != nil {
+		log.Printf("Error marshalling offsets: %v", err)
+		out = nil
+	}
+
+	return out
+}
+
+func (s *Store) save() {
+	if s.path == "" {
+		return
+	}
+
+	data := s.prepare()
+	if data == nil {
+		return
+	}
+
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		log.Printf("Error writing offsets to file: %v", err)
+	}
+}
+
+// GetOffset returns the saved offset, e.g. the file offset by the file path.
+func GetOffset(key string) (int64, bool) {
+	if globalStore == nil {
+		return 0, false
+	}
+
+	globalStore.mutex.Lock()
+	defer globalStore.mutex.Unlock()
+
+	offset, ok := globalStore.offsets[key]
+	return offset, ok
+}
+
+// SetOffset saves the offset.
+func SetOffset(key string, offset int64) {
+	if globalStore == nil {
+		return
+	}
+
+	globalStore.mutex.Lock()
+	defer globalStore.mutex.Unlock()
+
+	globalStore.offsets[key] = offset
+	globalStore.debounce.Emit()
+}
+
+// GetCursor returns the saved cursor, e.g. the journal cursor.
+func GetCursor(key string) string {
+	if globalStore == nil {
+		return ""
+	}
+
+	globalStore.mutex.Lock()
+	defer globalStore.mutex.Unlock()
+
+	return globalStore.cursors[key]
+}
+
+// SetCursor saves the cursor.
+func SetCursor(key string, cursor string) {
+	if globalStore == nil {
+		return
+	}
+
+	globalStore.mutex.Lock()
+	defer globalStore.mutex.Unlock()
+
+	globalStore.cursors[key] = cursor
+	globalStore.debounce.Emit()
+}
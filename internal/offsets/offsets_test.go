@@ -0,0 +1,41 @@
+package offsets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lib", "offsets.yaml")
+
+	require.NoError(t, Open(path), "failed to open offsets")
+
+	SetOffset("/var/log/app.log", 1024)
+	SetCursor("kmsg:/dev/kmsg", "test-boot:42")
+	SetCursor("journal:nginx.service", "s=abc;i=1f")
+	Close()
+
+	require.NoError(t, Open(path), "failed to open offsets")
+	defer Close()
+
+	offset, ok := GetOffset("/var/log/app.log")
+	require.True(t, ok)
+	require.Equal(t, int64(1024), offset)
+
+	_, ok = GetOffset("/var/log/unknown.log")
+	require.False(t, ok)
+
+	require.Equal(t, "test-boot:42", GetCursor("kmsg:/dev/kmsg"))
+	require.Equal(t, "s=abc;i=1f", GetCursor("journal:nginx.service"))
+	require.Equal(t, "", GetCursor("journal:unknown"))
+}
+
+func TestStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offsets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- invalid"), 0644))
+
+	require.ErrorContains(t, Open(path), "unmarshal offsets")
+}
@@ -9,4 +9,6 @@ type AppHandler interface {
 	GetStorage() storage.StorageDriver
 	GetFields(string) []*field.Field
 	GetAgents() []string
+	GetStats(string) map[string]int64
+	GetDeadLetter(string) string
 }
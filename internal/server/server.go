@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fugo-app/fugo/internal/field"
 	"github.com/fugo-app/fugo/internal/storage"
 )
 
@@ -45,6 +46,8 @@ func (sc *ServerConfig) Open(app AppHandler) error {
 	mux.HandleFunc("/api/query/{name}", sc.handleQuery)
 	mux.HandleFunc("/api/schema/{name}", sc.handleSchema)
 	mux.HandleFunc("/api/agents", sc.handleAgents)
+	mux.HandleFunc("/api/stats/{name}", sc.handleStats)
+	mux.HandleFunc("/api/dead_letter/{name}", sc.handleDeadLetter)
 
 	mw := sc.Cors.Middleware(mux)
 
@@ -91,6 +94,28 @@ func (sc *ServerConfig) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sc.query(w, r, name, sc.app.GetFields(name))
+}
+
+func (sc *ServerConfig) handleDeadLetter(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		http.Error(w, "Missing name parameter", http.StatusBadRequest)
+		return
+	}
+
+	table := sc.app.GetDeadLetter(name)
+	if table == "" {
+		http.Error(w, "Dead letter not enabled", http.StatusNotFound)
+		return
+	}
+
+	sc.query(w, r, table, nil)
+}
+
+// query sends the records matching the query parameters.
+// Fields are used to convert filter values with units, e.g. "duration__gt=1.5s" or "size__lt=2MB".
+func (sc *ServerConfig) query(w http.ResponseWriter, r *http.Request, name string, fields []*field.Field) {
 	// Get query parameters from URL
 	queryParams := r.URL.Query()
 
@@ -128,6 +153,16 @@ func (sc *ServerConfig) handleQuery(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		} else {
+			if f := findField(fields, key); f != nil {
+				v, err := f.FilterValue(value)
+				if err != nil {
+					message := fmt.Sprintf("Invalid filter value for key %s", key)
+					http.Error(w, message, http.StatusBadRequest)
+					return
+				}
+				value = v
+			}
+
 			if err := query.SetFilter(key, op, value); err != nil {
 				message := fmt.Sprintf("Invalid filter operator for key %s", key)
 				http.Error(w, message, http.StatusBadRequest)
@@ -143,6 +178,16 @@ func (sc *ServerConfig) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func findField(fields []*field.Field, name string) *field.Field {
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
 func (sc *ServerConfig) handleSchema(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 	if name == "" {
@@ -199,3 +244,33 @@ func (sc *ServerConfig) handleAgents(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error sending /api/agents response: %v", err)
 	}
 }
+
+func (sc *ServerConfig) handleStats(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		http.Error(w, "Missing name parameter", http.StatusBadRequest)
+		return
+	}
+
+	stats := sc.app.GetStats(name)
+	if stats == nil {
+		http.Error(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	type statsResponse struct {
+		Name  string           `json:"name"`
+		Stats map[string]int64 `json:"stats"`
+	}
+
+	response := statsResponse{
+		Name:  name,
+		Stats: stats,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error sending /api/stats/%s response: %v", name, err)
+	}
+}
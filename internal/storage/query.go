@@ -3,9 +3,13 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"net/netip"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fugo-app/fugo/internal/field"
 	"github.com/fugo-app/fugo/pkg/duration"
 )
 
@@ -21,6 +25,7 @@ type Query struct {
 
 type QueryOperator struct {
 	name string
+	path string // JSON path for the json fields, e.g. "$.user.id"
 	op   QueryOperatorType
 	ival int64
 	sval string
@@ -46,6 +51,9 @@ const (
 	// Time Operators
 	Since
 	Until
+
+	// IP Operators
+	InSubnet
 )
 
 var opmap = map[string]QueryOperatorType{
@@ -61,6 +69,8 @@ var opmap = map[string]QueryOperatorType{
 	"suffix": Suffix,
 	"since":  Since,
 	"until":  Until,
+
+	"in_subnet": InSubnet,
 }
 
 var stdTimeNow = time.Now
@@ -90,34 +100,97 @@ func (q *Query) SetBefore(before int64) {
 	q.before.Valid = true
 }
 
+// SetFilter adds the filter for the field.
+// Values of the json fields could be filtered by the dot-separated path,
+// e.g. "payload.user.id" or "payload.items.0".
 func (q *Query) SetFilter(name string, op string, val string) error {
 	opType, ok := opmap[op]
 	if !ok {
 		return fmt.Errorf("invalid operator: %s", op)
 	}
 
+	name, path, _ := strings.Cut(name, ".")
+	if path != "" {
+		p, err := parseJsonPath(path)
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+
+	filter := &QueryOperator{name: name, path: path, op: opType}
+
 	if opType >= Eq && opType <= Gte {
-		// Integer operators
+		// Integer operators, bool values are stored as 1 and 0
 		ival, err := strconv.ParseInt(val, 0, 64)
 		if err != nil {
-			return fmt.Errorf("invalid int value: %s", val)
+			bval, err := field.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("invalid int value: %s", val)
+			}
+			if bval {
+				ival = 1
+			}
 		}
-		q.filters = append(q.filters, &QueryOperator{name: name, op: opType, ival: ival})
+		filter.ival = ival
 	} else if opType >= Exact && opType <= Suffix {
 		// String operators
-		q.filters = append(q.filters, &QueryOperator{name: name, op: opType, sval: val})
+		filter.sval = val
 	} else if opType >= Since && opType <= Until {
 		// Time operators
 		timestamp, err := parseTimestamp(val)
 		if err != nil {
 			return fmt.Errorf("invalid timestamp value: %s, error: %w", val, err)
 		}
-		q.filters = append(q.filters, &QueryOperator{name: name, op: opType, ival: timestamp})
+		filter.ival = timestamp
+	} else if opType == InSubnet {
+		// IP operators, single address is a subnet with the full prefix
+		prefix, err := netip.ParsePrefix(val)
+		if err != nil {
+			addr, err := netip.ParseAddr(val)
+			if err != nil {
+				return fmt.Errorf("invalid subnet value: %s", val)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		filter.sval = prefix.Masked().String()
 	}
 
+	q.filters = append(q.filters, filter)
+
 	return nil
 }
 
+var reJsonKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// parseJsonPath converts the dot-separated path to the SQLite JSON path.
+// Numeric parts are array indexes: "items.0.id" is converted to "$.items[0].id".
+func parseJsonPath(path string) (string, error) {
+	var sb strings.Builder
+	sb.WriteByte('$')
+
+	for _, part := range strings.Split(path, ".") {
+		if _, err := strconv.ParseUint(part, 10, 32); err == nil {
+			sb.WriteString("[" + part + "]")
+		} else if reJsonKey.MatchString(part) {
+			sb.WriteString("." + part)
+		} else {
+			return "", fmt.Errorf("invalid json path: %s", path)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// column returns the SQL expression of the filtered value.
+func (op *QueryOperator) column() string {
+	if op.path == "" {
+		return fmt.Sprintf("`%s`", op.name)
+	}
+
+	return fmt.Sprintf("json_extract(`%s`, '%s')", op.name, op.path)
+}
+
 // parseTimestamp converts timestamp string to unix milliseconds.
 // Supported formats:
 // - "2006-01-02T15:04:05" - date and time format
@@ -36,6 +36,10 @@ func testQuery_CheckResult(t *testing.T, name string, storage StorageDriver, tes
 				var record map[string]any
 				require.NoError(t, json.Unmarshal(line, &record), "Failed to unmarshal JSON")
 
+				// ingestion time is set on insert
+				require.Contains(t, record, "_ingested", "Record should have ingestion time")
+				delete(record, "_ingested")
+
 				// convert float64 to int64
 				for k, v := range record {
 					if _, ok := v.(float64); ok {
@@ -413,3 +417,128 @@ func testQuery_Time(t *testing.T, storage StorageDriver) {
 
 	testQuery_CheckResult(t, name, storage, tests)
 }
+
+func testQuery_JSON(t *testing.T, storage StorageDriver) {
+	name := "test_query_json"
+
+	fields := []*field.Field{
+		{Name: "payload", Type: "json"},
+		{Name: "debug", Type: "bool"},
+	}
+
+	// Insert test data
+	testData := []map[string]any{
+		{"payload": `{"user":{"id":1,"name":"alice"},"tags":["a"]}`, "debug": true},
+		{"payload": `{"user":{"id":2,"name":"bob"},"tags":["b"]}`, "debug": false},
+		{"payload": `{"user":{"id":3,"name":"alex"},"tags":["a","b"]}`, "debug": false},
+	}
+
+	testStorage_InitDriver(t, name, storage, fields, testData)
+
+	tests := []*queryTest{
+		{
+			name: "json path int filter",
+			modifier: func(q *Query) {
+				q.SetFilter("payload.user.id", "gte", "2")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000002", "payload": `{"user":{"id":2,"name":"bob"},"tags":["b"]}`, "debug": int64(0)},
+				{"_cursor": "0000000000000003", "payload": `{"user":{"id":3,"name":"alex"},"tags":["a","b"]}`, "debug": int64(0)},
+			},
+		},
+		{
+			name: "json path string filter",
+			modifier: func(q *Query) {
+				q.SetFilter("payload.user.name", "prefix", "al")
+				q.SetFilter("payload.tags.0", "exact", "a")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000001", "payload": `{"user":{"id":1,"name":"alice"},"tags":["a"]}`, "debug": int64(1)},
+				{"_cursor": "0000000000000003", "payload": `{"user":{"id":3,"name":"alex"},"tags":["a","b"]}`, "debug": int64(0)},
+			},
+		},
+		{
+			name: "bool filter",
+			modifier: func(q *Query) {
+				q.SetFilter("debug", "eq", "true")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000001", "payload": `{"user":{"id":1,"name":"alice"},"tags":["a"]}`, "debug": int64(1)},
+			},
+		},
+		{
+			name: "bool filter with yes/no",
+			modifier: func(q *Query) {
+				q.SetFilter("debug", "ne", "yes")
+				q.SetFilter("debug", "eq", "Off")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000002", "payload": `{"user":{"id":2,"name":"bob"},"tags":["b"]}`, "debug": int64(0)},
+				{"_cursor": "0000000000000003", "payload": `{"user":{"id":3,"name":"alex"},"tags":["a","b"]}`, "debug": int64(0)},
+			},
+		},
+	}
+
+	testQuery_CheckResult(t, name, storage, tests)
+}
+
+func testQuery_IP(t *testing.T, storage StorageDriver) {
+	name := "test_query_ip"
+
+	fields := []*field.Field{
+		{Name: "client", Type: "ip"},
+	}
+
+	// Insert test data
+	testData := []map[string]any{
+		{"client": "10.0.0.1"},
+		{"client": "192.168.1.10"},
+		{"client": "2001:db8::1"},
+		{"client": ""},
+	}
+
+	testStorage_InitDriver(t, name, storage, fields, testData)
+
+	tests := []*queryTest{
+		{
+			name: "ipv4 subnet",
+			modifier: func(q *Query) {
+				q.SetFilter("client", "in_subnet", "10.0.0.0/8")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000001", "client": "10.0.0.1"},
+			},
+		},
+		{
+			name: "ipv6 subnet",
+			modifier: func(q *Query) {
+				q.SetFilter("client", "in_subnet", "2001:db8::/32")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000003", "client": "2001:db8::1"},
+			},
+		},
+		{
+			name: "single address",
+			modifier: func(q *Query) {
+				q.SetFilter("client", "in_subnet", "192.168.1.10")
+			},
+			want: []map[string]any{
+				{"_cursor": "0000000000000002", "client": "192.168.1.10"},
+			},
+		},
+	}
+
+	testQuery_CheckResult(t, name, storage, tests)
+}
+
+func TestQuery_SetFilter(t *testing.T) {
+	q := NewQuery("test")
+
+	require.NoError(t, q.SetFilter("payload.items.0.id", "eq", "1"))
+	require.Equal(t, "json_extract(`payload`, '$.items[0].id')", q.filters[0].column())
+
+	require.ErrorContains(t, q.SetFilter("payload.a'b", "eq", "1"), "invalid json path")
+	require.ErrorContains(t, q.SetFilter("client", "in_subnet", "10.0.0.0/33"), "invalid subnet value")
+	require.ErrorContains(t, q.SetFilter("status", "eq", "abc"), "invalid int value")
+}
@@ -6,17 +6,55 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
 	"github.com/fugo-app/fugo/internal/field"
 )
 
+// sqliteDriver is the SQLite driver with custom functions.
+const sqliteDriver = "sqlite3_fugo"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("in_subnet", inSubnet, true)
+		},
+	})
+}
+
+// inSubnet checks if the IP address is in the subnet, e.g. in_subnet(ip, '10.0.0.0/8').
+// NULL and non-text values are not in any subnet.
+func inSubnet(value any, subnet string) bool {
+	var addr string
+	switch v := value.(type) {
+	case string:
+		addr = v
+	case []byte:
+		addr = string(v)
+	default:
+		return false
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return false
+	}
+
+	return prefix.Contains(ip.Unmap())
+}
+
 type SQLiteStorage struct {
 	Path string `yaml:"path"`
 
@@ -74,7 +112,7 @@ func (ss *SQLiteStorage) Open() error {
 		sourceName = fmt.Sprintf("%s?%s", sourceName, params.Encode())
 	}
 
-	db, err := sql.Open("sqlite3", sourceName)
+	db, err := sql.Open(sqliteDriver, sourceName)
 	if err != nil {
 		return fmt.Errorf("open sqlite database: %w", err)
 	}
@@ -156,38 +194,41 @@ func (ss *SQLiteStorage) Query(w io.Writer, q *Query) error {
 	for _, filter := range q.filters {
 		switch filter.op {
 		case Eq:
-			conditions = append(conditions, fmt.Sprintf("`%s` = ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s = ?", filter.column()))
 			args = append(args, filter.ival)
 		case Ne:
-			conditions = append(conditions, fmt.Sprintf("`%s` != ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s != ?", filter.column()))
 			args = append(args, filter.ival)
 		case Lt:
-			conditions = append(conditions, fmt.Sprintf("`%s` < ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s < ?", filter.column()))
 			args = append(args, filter.ival)
 		case Lte:
-			conditions = append(conditions, fmt.Sprintf("`%s` <= ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s <= ?", filter.column()))
 			args = append(args, filter.ival)
 		case Gt:
-			conditions = append(conditions, fmt.Sprintf("`%s` > ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s > ?", filter.column()))
 			args = append(args, filter.ival)
 		case Gte:
-			conditions = append(conditions, fmt.Sprintf("`%s` >= ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s >= ?", filter.column()))
 			args = append(args, filter.ival)
 		case Exact:
-			conditions = append(conditions, fmt.Sprintf("`%s` = ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s = ?", filter.column()))
 			args = append(args, filter.sval)
 		case Like:
-			conditions = append(conditions, fmt.Sprintf("`%s` LIKE ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s LIKE ?", filter.column()))
 			value := "%" + filter.sval + "%"
 			args = append(args, value)
 		case Prefix:
-			conditions = append(conditions, fmt.Sprintf("`%s` LIKE ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s LIKE ?", filter.column()))
 			value := filter.sval + "%"
 			args = append(args, value)
 		case Suffix:
-			conditions = append(conditions, fmt.Sprintf("`%s` LIKE ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s LIKE ?", filter.column()))
 			value := "%" + filter.sval
 			args = append(args, value)
+		case InSubnet:
+			conditions = append(conditions, fmt.Sprintf("in_subnet(%s, ?)", filter.column()))
+			args = append(args, filter.sval)
 		case Since:
 			if q.after.Valid {
 				// Could be used only with before-cursor. For example:
@@ -195,7 +236,7 @@ func (ss *SQLiteStorage) Query(w io.Writer, q *Query) error {
 				return nil
 			}
 			reverse = false
-			conditions = append(conditions, fmt.Sprintf("`%s` > ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s > ?", filter.column()))
 			args = append(args, filter.ival)
 		case Until:
 			if q.before.Valid {
@@ -204,7 +245,7 @@ func (ss *SQLiteStorage) Query(w io.Writer, q *Query) error {
 				return nil
 			}
 			reverse = true
-			conditions = append(conditions, fmt.Sprintf("`%s` < ?", filter.name))
+			conditions = append(conditions, fmt.Sprintf("%s < ?", filter.column()))
 			args = append(args, filter.ival)
 		}
 	}
@@ -261,7 +302,7 @@ func (ss *SQLiteStorage) getSqlType(f *field.Field) string {
 	switch f.Type {
 	case "string":
 		return "TEXT"
-	case "int", "time":
+	case "int", "time", "bool", "duration", "bytes":
 		return "INTEGER"
 	case "float":
 		return "REAL"
@@ -368,6 +409,7 @@ func (ss *SQLiteStorage) createTable(name string, fields []*field.Field) error {
 	var columns []string
 
 	columns = append(columns, "`_cursor` INTEGER PRIMARY KEY AUTOINCREMENT")
+	columns = append(columns, "`_ingested` INTEGER")
 
 	for _, f := range fields {
 		fieldType := ss.getSqlType(f)
@@ -394,6 +436,10 @@ func (ss *SQLiteStorage) createTable(name string, fields []*field.Field) error {
 }
 
 func (ss *SQLiteStorage) migrateTable(name string, fields []*field.Field) error {
+	if err := ss.migrateIngested(name); err != nil {
+		return err
+	}
+
 	currentColumns, err := ss.getColumns(name)
 	if err != nil {
 		return fmt.Errorf("get columns: %w", err)
@@ -462,10 +508,31 @@ func (ss *SQLiteStorage) migrateTable(name string, fields []*field.Field) error
 	return nil
 }
 
+// migrateIngested adds the ingestion time column to tables created before it was introduced.
+func (ss *SQLiteStorage) migrateIngested(name string) error {
+	var exists bool
+	const checkQuery = "SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = '_ingested'"
+	if err := ss.db.QueryRow(checkQuery, name).Scan(&exists); err != nil {
+		return fmt.Errorf("check column _ingested: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	alterQuery := fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `_ingested` INTEGER", name)
+	if _, err := ss.db.Exec(alterQuery); err != nil {
+		return fmt.Errorf("add column _ingested: %w", err)
+	}
+
+	return nil
+}
+
 func (ss *SQLiteStorage) insertData(name string, data map[string]any) error {
-	columns := []string{}
-	placeholders := []string{}
-	values := []any{}
+	// Ingestion time is stored for every record to measure the pipeline lag
+	columns := []string{"`_ingested`"}
+	placeholders := []string{"?"}
+	values := []any{stdTimeNow().UnixMilli()}
 
 	for col, val := range data {
 		columns = append(columns, fmt.Sprintf("`%s`", col)) // экранируем имя столбца
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -137,6 +138,42 @@ func TestSQLiteStorage_migrateTable_AddColumn(t *testing.T) {
 	})
 }
 
+func TestSQLiteStorage_migrateTable_Ingested(t *testing.T) {
+	storage := &SQLiteStorage{Path: ":memory:"}
+	require.NoError(t, storage.Open(), "Failed to open SQLite database")
+	defer storage.Close()
+
+	name := "test_ingested"
+	fields := testSqlite_InitFields(t, []*field.Field{
+		{Name: "time", Type: "time"},
+		{Name: "level", Type: "string"},
+	})
+
+	// Table created before the ingestion time column
+	_, err := storage.db.Exec("CREATE TABLE `test_ingested` " +
+		"(`_cursor` INTEGER PRIMARY KEY AUTOINCREMENT, `time` INTEGER, `level` TEXT)")
+	require.NoError(t, err, "Failed to create legacy table")
+
+	require.NoError(t, storage.Migrate(name, fields), "Failed to migrate table")
+	testSqlite_VerifyDB(t, storage, name, fields)
+
+	stdTimeNow = func() time.Time {
+		return time.UnixMilli(1672574400123)
+	}
+	defer func() {
+		stdTimeNow = time.Now
+	}()
+
+	record := map[string]any{"time": int64(1672574300000), "level": "info"}
+	require.NoError(t, storage.insertData(name, record), "Failed to insert data")
+
+	var ingested int64
+	row := storage.db.QueryRow(fmt.Sprintf("SELECT `_ingested` FROM `%s` LIMIT 1", name))
+	require.NoError(t, row.Scan(&ingested), "Failed to query ingestion time")
+	require.Equal(t, int64(1672574400123), ingested)
+	require.NotContains(t, record, "_ingested", "Record should not be modified")
+}
+
 func TestSQLiteStorage_migrateTable_RemoveColumn(t *testing.T) {
 	storage := &SQLiteStorage{Path: ":memory:"}
 	require.NoError(t, storage.Open(), "Failed to open SQLite database")
@@ -349,6 +386,14 @@ func TestSQLiteStorage_Query(t *testing.T) {
 	t.Run("time", func(t *testing.T) {
 		testQuery_Time(t, storage)
 	})
+
+	t.Run("json", func(t *testing.T) {
+		testQuery_JSON(t, storage)
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		testQuery_IP(t, storage)
+	})
 }
 
 func testSqlite_InitFields(t *testing.T, fields []*field.Field) []*field.Field {
@@ -0,0 +1,52 @@
+package bytesize
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var reMatch = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*(([kmgtpe])(i?b)?|b)?$`)
+
+// Units are binary, 1KB is 1024 bytes.
+var unitMap = map[byte]float64{
+	'k': 1 << 10,
+	'm': 1 << 20,
+	'g': 1 << 30,
+	't': 1 << 40,
+	'p': 1 << 50,
+	'e': 1 << 60,
+}
+
+func Match(input string) bool {
+	return reMatch.MatchString(input)
+}
+
+// Parse parses the size string and returns number of bytes,
+// e.g. "512", "100B", "1.5KB", "3MiB", "2g".
+// Value without unit is in bytes.
+func Parse(input string) (int64, error) {
+	match := reMatch.FindStringSubmatch(strings.TrimSpace(input))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size: %s", input)
+	}
+
+	unit := float64(1)
+	if match[3] != "" {
+		unit = unitMap[strings.ToLower(match[3])[0]]
+	}
+
+	if value, err := strconv.ParseInt(match[1], 10, 64); err == nil && unit == 1 {
+		return value, nil
+	}
+
+	value, _ := strconv.ParseFloat(match[1], 64)
+	size := math.Round(value * unit)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size is out of range: %s", input)
+	}
+
+	return int64(size), nil
+}
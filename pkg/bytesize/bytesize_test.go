@@ -0,0 +1,97 @@
+package bytesize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{
+			input: "512",
+			want:  512,
+		},
+		{
+			input: "100B",
+			want:  100,
+		},
+		{
+			input: "1.5KB",
+			want:  1536,
+		},
+		{
+			input: "1k",
+			want:  1024,
+		},
+		{
+			input: "3MiB",
+			want:  3 * 1024 * 1024,
+		},
+		{
+			input: "2 GB",
+			want:  2 * 1024 * 1024 * 1024,
+		},
+		{
+			input: "0.5t",
+			want:  512 * 1024 * 1024 * 1024,
+		},
+		{
+			input:   "16EB",
+			wantErr: true,
+		},
+		{
+			input: "1.5",
+			want:  2,
+		},
+		{
+			input:   "invalid",
+			wantErr: true,
+		},
+		{
+			input:   "10x",
+			wantErr: true,
+		},
+		{
+			input:   "KB",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result, err := Parse(test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, result)
+			}
+		})
+	}
+}
+
+func Test_Match(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"1024", true},
+		{"1.5KB", true},
+		{"3MiB", true},
+		{"10ib", false},
+		{"1h", false},
+		{"invalid", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result := Match(test.input)
+			assert.Equal(t, test.want, result)
+		})
+	}
+}
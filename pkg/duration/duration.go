@@ -2,25 +2,33 @@ package duration
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
-var reMatch = regexp.MustCompile(`(?i)^(\d+[smhd]?)+$`)
-var reParts = regexp.MustCompile(`(?i)(\d+)([smhd]|$)`)
-
-var unitMap = map[byte]time.Duration{
-	's': time.Second,
-	'm': time.Minute,
-	'h': time.Hour,
-	'd': time.Hour * 24,
+var reMatch = regexp.MustCompile(`(?i)^(\d+(\.\d+)?(ms|us|µs|ns|[smhd])?)+$`)
+var reParts = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)(ms|us|µs|ns|[smhd]|$)`)
+
+var unitMap = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  time.Hour * 24,
 }
 
 func Match(input string) bool {
 	return reMatch.MatchString(input)
 }
 
+// Parse parses the duration string, e.g. "1h30m", "2.5s", "123.4ms".
+// Value without unit is in seconds.
 func Parse(input string) (time.Duration, error) {
 	matches := reParts.FindAllStringSubmatch(input, -1)
 	if len(matches) == 0 {
@@ -34,18 +42,17 @@ func Parse(input string) (time.Duration, error) {
 			return 0, fmt.Errorf("invalid duration format: %s", input)
 		}
 
-		value, _ := strconv.Atoi(match[1])
-
 		unit := time.Second
 		if match[2] != "" {
-			key := match[2][0]
-			if key >= 'A' && key <= 'Z' {
-				key += 'a' - 'A' // Convert to lowercase
-			}
-			unit = unitMap[key]
+			unit = unitMap[strings.ToLower(match[2])]
 		}
 
-		total += time.Duration(value) * unit
+		if value, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			total += time.Duration(value) * unit
+		} else {
+			value, _ := strconv.ParseFloat(match[1], 64)
+			total += time.Duration(math.Round(value * float64(unit)))
+		}
 	}
 
 	return total, nil
@@ -49,6 +49,34 @@ func Test_Parse(t *testing.T) {
 			input: "1m30",
 			want:  time.Minute + (30 * time.Second),
 		},
+		{
+			input: "123.4ms",
+			want:  123400 * time.Microsecond,
+		},
+		{
+			input: "2.1s",
+			want:  2100 * time.Millisecond,
+		},
+		{
+			input: "1.5h",
+			want:  90 * time.Minute,
+		},
+		{
+			input: "1m30s250ms",
+			want:  90*time.Second + 250*time.Millisecond,
+		},
+		{
+			input: "350us",
+			want:  350 * time.Microsecond,
+		},
+		{
+			input: "12µs",
+			want:  12 * time.Microsecond,
+		},
+		{
+			input: "800NS",
+			want:  800 * time.Nanosecond,
+		},
 		{
 			input:   "invalid",
 			wantErr: true,
@@ -83,7 +111,11 @@ func Test_Match(t *testing.T) {
 		{"1d", true},
 		{"1h30m", true},
 		{"2d3h", true},
+		{"1.5s", true},
+		{"123.4ms", true},
+		{"250us", true},
 		{"1d 14h 30m", false},
+		{"1.s", false},
 		{"invalid", false},
 		{"10x", false},
 	}
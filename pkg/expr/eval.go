@@ -0,0 +1,192 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type node interface {
+	eval(env Env) any
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n *literalNode) eval(env Env) any {
+	return n.value
+}
+
+type identNode struct {
+	name string
+}
+
+func (n *identNode) eval(env Env) any {
+	return env(n.name)
+}
+
+type notNode struct {
+	x node
+}
+
+func (n *notNode) eval(env Env) any {
+	return !truthy(n.x.eval(env))
+}
+
+type andNode struct {
+	left  node
+	right node
+}
+
+func (n *andNode) eval(env Env) any {
+	return truthy(n.left.eval(env)) && truthy(n.right.eval(env))
+}
+
+type orNode struct {
+	left  node
+	right node
+}
+
+func (n *orNode) eval(env Env) any {
+	return truthy(n.left.eval(env)) || truthy(n.right.eval(env))
+}
+
+type matchNode struct {
+	x      node
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (n *matchNode) eval(env Env) any {
+	return n.re.MatchString(toString(n.x.eval(env))) != n.negate
+}
+
+type compareNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *compareNode) eval(env Env) any {
+	left := n.left.eval(env)
+	right := n.right.eval(env)
+
+	cmp, ok := compare(left, right)
+	if !ok {
+		// Values could not be ordered, only inequality is true
+		return n.op == "!="
+	}
+
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return false
+}
+
+// compare returns -1, 0, or 1 and true if values are comparable.
+// Numbers are compared numerically, strings are converted to numbers
+// if the other value is a number. Booleans support only equality.
+func compare(left, right any) (int, bool) {
+	ln, lnum := toNumber(left)
+	rn, rnum := toNumber(right)
+
+	_, lstr := left.(string)
+	_, rstr := right.(string)
+
+	switch {
+	case lnum && rnum:
+		return compareOrdered(ln, rn), true
+	case lnum && rstr:
+		if v, err := strconv.ParseFloat(right.(string), 64); err == nil {
+			return compareOrdered(ln, v), true
+		}
+		return 0, false
+	case lstr && rnum:
+		if v, err := strconv.ParseFloat(left.(string), 64); err == nil {
+			return compareOrdered(v, rn), true
+		}
+		return 0, false
+	}
+
+	lb, lbool := left.(bool)
+	rb, rbool := right.(bool)
+	if lbool || rbool {
+		if lbool && rbool && lb == rb {
+			return 0, true
+		}
+		return 0, false
+	}
+
+	return compareOrdered(toString(left), toString(right)), true
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+
+	return fmt.Sprint(v)
+}
+
+// truthy converts the value to boolean: false, nil, zero, and empty string are false.
+func truthy(v any) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case string:
+		return b != ""
+	}
+
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+
+	return true
+}
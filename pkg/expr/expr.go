@@ -0,0 +1,219 @@
+// Package expr implements boolean expressions to match log records.
+//
+// Supported syntax:
+//
+//	status >= 500 && method != "GET"
+//	path =~ "^/health" || !(level == "error")
+//
+// Operands are identifiers, quoted strings, numbers, true and false.
+// Comparison operators: ==, !=, <, <=, >, >=, =~ (regex match), !~ (regex not match).
+// Logical operators: &&, ||, !, and parentheses for grouping.
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Env returns the value of the identifier. Missing values should be returned as nil.
+type Env func(name string) any
+
+// Expr is a compiled expression.
+type Expr struct {
+	source string
+	root   node
+	names  []string
+}
+
+// Compile parses the expression.
+func Compile(source string) (*Expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	return &Expr{
+		source: source,
+		root:   root,
+		names:  p.names,
+	}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Names returns the list of identifiers used in the expression.
+func (e *Expr) Names() []string {
+	return e.names
+}
+
+// Match evaluates the expression and returns the result as boolean.
+func (e *Expr) Match(env Env) bool {
+	return truthy(e.root.eval(env))
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	names  []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokenOp && t.value == op
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind != tokenOp {
+		return left, nil
+	}
+
+	switch t.value {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{t.value, left, right}, nil
+
+	case "=~", "!~":
+		p.next()
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, fmt.Errorf("expected regular expression string at position %d", pattern.pos)
+		}
+		re, err := regexp.Compile(pattern.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %w", pattern.pos, err)
+		}
+		return &matchNode{left, re, t.value == "!~"}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOp("!") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", end.pos)
+		}
+		return x, nil
+
+	case tokenString:
+		return &literalNode{t.value}, nil
+
+	case tokenNumber:
+		if v, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return &literalNode{v}, nil
+		}
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &literalNode{v}, nil
+
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		}
+		p.names = append(p.names, t.value)
+		return &identNode{t.value}, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
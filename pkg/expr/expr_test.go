@@ -0,0 +1,93 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Match(t *testing.T) {
+	values := map[string]any{
+		"status":     int64(503),
+		"latency":    0.25,
+		"method":     "GET",
+		"path":       "/health/live",
+		"debug":      true,
+		"raw.status": "503",
+		"raw.empty":  "",
+	}
+
+	env := func(name string) any {
+		return values[name]
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`status == 503`, true},
+		{`status != 503`, false},
+		{`status >= 500 && status < 600`, true},
+		{`latency > 0.1`, true},
+		{`latency <= -1`, false},
+		{`method == "GET"`, true},
+		{`method == 'POST'`, false},
+		{`method < "POST"`, true},
+		{`path =~ "^/health"`, true},
+		{`path !~ "^/health"`, false},
+		{`path =~ "/\w+/live$"`, true},
+		{`raw.status == 503`, true},
+		{`raw.status >= "500"`, true},
+		{`raw.empty`, false},
+		{`debug`, true},
+		{`!debug`, false},
+		{`debug == true`, true},
+		{`debug == 1`, false},
+		{`debug != 1`, true},
+		{`method == 1`, false},
+		{`missing`, false},
+		{`missing == ""`, true},
+		{`!(status == 200) && (method == "POST" || path =~ "live")`, true},
+		{`status == 200 || method == "GET" && debug`, true},
+		{`status == 200 || method == "POST" && debug`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, e.Match(env))
+		})
+	}
+}
+
+func TestExpr_Names(t *testing.T) {
+	e, err := Compile(`status >= 500 && raw.path =~ "x" || true`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"status", "raw.path"}, e.Names())
+	require.Equal(t, `status >= 500 && raw.path =~ "x" || true`, e.String())
+}
+
+func TestExpr_CompileError(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{``, "unexpected end of expression"},
+		{`status ==`, "unexpected end of expression"},
+		{`(status == 1`, "expected ')'"},
+		{`status == 1)`, `unexpected ")"`},
+		{`status = 1`, "unexpected character '='"},
+		{`path =~ path`, "expected regular expression string"},
+		{`path =~ "("`, "invalid regular expression"},
+		{`method == "GET`, "unterminated string"},
+		{`latency > 1.2.3`, "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// Operators ordered by length to match the longest first.
+var operators = []string{
+	"==", "!=", "<=", ">=", "=~", "!~", "&&", "||",
+	"<", ">", "!",
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '.'
+}
+
+// tokenize splits the expression into tokens.
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+
+	i := 0
+	for i < len(input) {
+		c := input[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+
+		case c == '"' || c == '\'':
+			value, n, err := readString(input[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at position %d", err, i)
+			}
+			tokens = append(tokens, token{tokenString, value, i})
+			i += n
+
+		case isDigit(c) || (c == '-' && i+1 < len(input) && isDigit(input[i+1])):
+			start := i
+			i++
+			for i < len(input) && (isDigit(input[i]) || input[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, input[start:i], start})
+
+		case isIdentStart(c):
+			start := i
+			for i < len(input) && isIdentChar(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, input[start:i], start})
+
+		default:
+			op := ""
+			for _, item := range operators {
+				if strings.HasPrefix(input[i:], item) {
+					op = item
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{tokenOp, op, i})
+			i += len(op)
+		}
+	}
+
+	tokens = append(tokens, token{tokenEOF, "", len(input)})
+
+	return tokens, nil
+}
+
+// readString reads the quoted string and returns the value and the number of consumed bytes.
+// Backslash escapes the quote and the backslash, other escapes are kept as is
+// to write regular expressions without double escaping, e.g. "\d+".
+func readString(input string) (string, int, error) {
+	quote := input[0]
+
+	var sb strings.Builder
+
+	for i := 1; i < len(input); i++ {
+		c := input[i]
+
+		switch {
+		case c == quote:
+			return sb.String(), i + 1, nil
+		case c == '\\' && i+1 < len(input):
+			next := input[i+1]
+			switch next {
+			case quote, '\\':
+				sb.WriteByte(next)
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(c)
+				sb.WriteByte(next)
+			}
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,43 @@
+package tailbuf
+
+// TailBuffer is a writer that keeps only the last bytes written,
+// e.g. stderr of the long-running command for the error message.
+type TailBuffer struct {
+	size      int
+	buf       []byte
+	truncated bool
+}
+
+func NewTailBuffer(size int) *TailBuffer {
+	return &TailBuffer{
+		size: size,
+		buf:  make([]byte, 0, size),
+	}
+}
+
+func (t *TailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if len(p) >= t.size {
+		t.truncated = t.truncated || len(t.buf) > 0 || len(p) > t.size
+		t.buf = append(t.buf[:0], p[len(p)-t.size:]...)
+		return n, nil
+	}
+
+	if over := len(t.buf) + len(p) - t.size; over > 0 {
+		t.truncated = true
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	t.buf = append(t.buf, p...)
+
+	return n, nil
+}
+
+// String returns the kept bytes, prefixed with "..." if the beginning was dropped.
+func (t *TailBuffer) String() string {
+	if t.truncated {
+		return "..." + string(t.buf)
+	}
+
+	return string(t.buf)
+}
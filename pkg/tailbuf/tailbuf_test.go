@@ -0,0 +1,31 @@
+package tailbuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailBuffer(t *testing.T) {
+	b := NewTailBuffer(8)
+
+	b.Write([]byte("abc"))
+	b.Write([]byte("def"))
+	require.Equal(t, "abcdef", b.String())
+
+	// Beginning is dropped when the size is exceeded
+	n, err := b.Write([]byte("ghij"))
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, "...cdefghij", b.String())
+
+	// Single write larger than the size
+	b = NewTailBuffer(4)
+	b.Write([]byte("0123456789"))
+	require.Equal(t, "...6789", b.String())
+
+	// Exact size is not truncated
+	b = NewTailBuffer(4)
+	b.Write([]byte("0123"))
+	require.Equal(t, "0123", b.String())
+}